@@ -2,8 +2,10 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
-	"log"
 	"net/http"
 	"os"
 	"os/signal"
@@ -12,64 +14,348 @@ import (
 
 	"github.com/gorilla/mux"
 
+	"GO-API/internal/config"
+	"GO-API/internal/domain/model"
+	"GO-API/internal/gateway"
 	"GO-API/internal/infrastructure/database/postgres"
 	"GO-API/internal/infrastructure/processor"
+	"GO-API/internal/infrastructure/scheduler"
 	"GO-API/internal/interface/handler"
 	"GO-API/internal/interface/middleware"
+	"GO-API/internal/pkg/auth"
+	"GO-API/internal/pkg/health"
 	"GO-API/internal/pkg/logger"
+	"GO-API/internal/pkg/metrics"
+	"GO-API/internal/pkg/ratelimit"
+	"GO-API/internal/pkg/tracing"
 	"GO-API/internal/usecase"
 )
 
 func main() {
-	config := &postgres.Config{
-		Host:     getEnv("DB_HOST", "localhost"),
-		Port:     5432,
-		User:     getEnv("DB_USER", "postgres"),
-		Password: getEnv("DB_PASSWORD", "postgres"),
-		DBName:   getEnv("DB_NAME", "go_api"),
-		SSLMode:  "disable",
+	loaded, err := config.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
 	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	cfg := loaded.Config
+	if loaded.PrintOnly {
+		for _, setting := range loaded.Effective() {
+			fmt.Printf("%s=%s\t# %s\n", setting.Name, setting.Value, setting.Source)
+		}
+		return
+	}
+
+	if err := logger.Init(os.Stdout, cfg.Log.Format, cfg.Log.LogLevel()); err != nil {
+		logger.Fatal("Invalid logging configuration", "error", err)
+	}
+	logger.Info("Effective configuration", "settings", loaded.Effective())
 
-	//start transaction to connect DB
-	db, err := postgres.NewConnection(config)
+	shutdownTracing, err := tracing.Init(context.Background(), tracing.Config{
+		Exporter:    cfg.Tracing.Exporter,
+		ServiceName: cfg.Tracing.ServiceName,
+		SampleRatio: cfg.Tracing.SampleRatio,
+		Output:      os.Stdout,
+	})
 	if err != nil {
-		logger.Error("Failed to connect to database: %v", err)
+		logger.Fatal("Invalid tracing configuration", "error", err)
+	}
+
+	jwtConfig := auth.Config{
+		HMACSecret: cfg.Auth.JWTSecret,
+		Issuer:     cfg.Auth.JWTIssuer,
+		Audience:   cfg.Auth.JWTAudience,
+		Leeway:     cfg.Auth.JWTLeeway,
+	}
+	if cfg.Auth.JWKS != "" {
+		keySet, err := auth.NewKeySet(cfg.Auth.JWKS, cfg.Auth.JWKSRefreshInterval)
+		if err != nil {
+			logger.Error("Failed to load JWKS", "error", err)
+			os.Exit(1)
+		}
+		keySet.Start()
+		defer keySet.Stop()
+		jwtConfig.KeySet = keySet
+	}
+
+	db, err := postgres.NewConnection(context.Background(), &postgres.Config{
+		URL:              cfg.Database.URL,
+		Host:             cfg.Database.Host,
+		Port:             cfg.Database.Port,
+		User:             cfg.Database.User,
+		Password:         cfg.Database.Password,
+		DBName:           cfg.Database.Name,
+		SSLMode:          cfg.Database.SSLMode,
+		SSLRootCert:      cfg.Database.SSLRootCert,
+		SSLCert:          cfg.Database.SSLCert,
+		SSLKey:           cfg.Database.SSLKey,
+		ApplicationName:  cfg.Database.ApplicationName,
+		StatementTimeout: cfg.Database.StatementTimeout,
+		MaxOpenConns:     cfg.Database.MaxOpenConns,
+		MaxIdleConns:     cfg.Database.MaxIdleConns,
+		ConnMaxLifetime:  cfg.Database.ConnMaxLifetime,
+		ConnMaxIdleTime:  cfg.Database.ConnMaxIdleTime,
+		ConnectTimeout:   cfg.Database.ConnectTimeout,
+	})
+	if err != nil {
+		logger.Error("Failed to connect to database", "error", err)
 		os.Exit(1)
 	}
 	logger.Info("Successfully connected to database")
 	defer db.Close()
+	metrics.RegisterDB(db, "postgres")
+
+	merchantRepo := postgres.NewMerchantRepository(db)
+	if err := merchantRepo.InitTable(); err != nil {
+		logger.Fatal("Failed to init tables", "error", err)
+	}
 
 	paymentRepo := postgres.NewPaymentRepository(db)
 
 	if err := paymentRepo.InitTable(); err != nil {
-		log.Fatalf("Failed to init tables: %v", err)
+		logger.Fatal("Failed to init tables", "error", err)
+	}
+
+	planRepo := postgres.NewPlanRepository(db)
+	if err := planRepo.InitTable(); err != nil {
+		logger.Fatal("Failed to init tables", "error", err)
+	}
+
+	subscriptionRepo := postgres.NewSubscriptionRepository(db)
+	if err := subscriptionRepo.InitTable(); err != nil {
+		logger.Fatal("Failed to init tables", "error", err)
+	}
+
+	invoiceRepo := postgres.NewInvoiceRepository(db)
+	if err := invoiceRepo.InitTable(); err != nil {
+		logger.Fatal("Failed to init tables", "error", err)
+	}
+
+	receiptRepo := postgres.NewReceiptRepository(db)
+	if err := receiptRepo.InitTable(); err != nil {
+		logger.Fatal("Failed to init tables", "error", err)
 	}
 
-	paymentProcessor := processor.NewPaymentProcessor()
+	auditRepo := postgres.NewAuditRepository(db)
+	if err := auditRepo.InitTable(); err != nil {
+		logger.Fatal("Failed to init tables", "error", err)
+	}
 
-	paymentUseCase := usecase.NewPaymentUseCase(paymentRepo, paymentProcessor)
+	apiClientRepo := postgres.NewAPIClientRepository(db)
+	if err := apiClientRepo.InitTable(); err != nil {
+		logger.Fatal("Failed to init tables", "error", err)
+	}
+
+	refreshTokenRepo := postgres.NewRefreshTokenRepository(db)
+	if err := refreshTokenRepo.InitTable(); err != nil {
+		logger.Fatal("Failed to init tables", "error", err)
+	}
+
+	tokenDenylist := postgres.NewTokenDenylist(db)
+	if err := tokenDenylist.InitTable(); err != nil {
+		logger.Fatal("Failed to init tables", "error", err)
+	}
+
+	apiKeyRepo := postgres.NewAPIKeyRepository(db)
+	if err := apiKeyRepo.InitTable(); err != nil {
+		logger.Fatal("Failed to init tables", "error", err)
+	}
+
+	callbackNonceRepo := postgres.NewCallbackNonceRepository(db)
+	if err := callbackNonceRepo.InitTable(); err != nil {
+		logger.Fatal("Failed to init tables", "error", err)
+	}
+
+	testDataRepo := postgres.NewTestDataRepository(db)
+
+	jwtConfig.Denylist = tokenDenylist
+	jwtAuth, err := auth.New(jwtConfig)
+	if err != nil {
+		logger.Error("Invalid JWT configuration, set JWT_SECRET or JWT_JWKS", "error", err)
+		os.Exit(1)
+	}
+
+	defaultIssuer := model.InvoiceIssuer{
+		Name:               cfg.Invoice.IssuerName,
+		RegistrationNumber: cfg.Invoice.IssuerRegistrationNumber,
+		Address:            cfg.Invoice.IssuerAddress,
+	}
+
+	liveProcessor := processor.NewPaymentProcessor()
+	paymentProcessor := processor.NewRouter(
+		processor.NewInstrumented("live", liveProcessor),
+		processor.NewInstrumented("test", processor.NewSimulatedProcessor()))
+
+	// Every configured callback provider currently notifies through the
+	// acquirer served by the live processor.
+	callbackSecrets, _ := cfg.Callbacks.SecretMap()
+	callbackAdapters := make(map[string]gateway.CallbackAdapter, len(callbackSecrets))
+	for provider := range callbackSecrets {
+		callbackAdapters[provider] = liveProcessor
+	}
+
+	auditUseCase := usecase.NewAuditUseCase(auditRepo)
+	paymentLimits := usecase.PaymentLimits{
+		MinAmount:  cfg.Payments.MinAmount,
+		MaxAmount:  cfg.Payments.MaxAmount,
+		Currencies: cfg.Payments.Currencies,
+	}
+	merchantUseCase := usecase.NewMerchantUseCase(merchantRepo, auditUseCase, paymentLimits, defaultIssuer)
+	paymentUseCase := usecase.NewPaymentUseCase(paymentRepo, paymentProcessor, merchantUseCase, auditUseCase)
+	subscriptionUseCase := usecase.NewSubscriptionUseCase(planRepo, subscriptionRepo, paymentUseCase, merchantUseCase)
+	invoiceUseCase := usecase.NewInvoiceUseCase(invoiceRepo, paymentUseCase, merchantUseCase, paymentLimits)
+	receiptUseCase := usecase.NewReceiptUseCase(receiptRepo, paymentRepo, merchantUseCase)
+	authUseCase := usecase.NewAuthUseCase(apiClientRepo, refreshTokenRepo, tokenDenylist, jwtAuth, auditUseCase,
+		cfg.Auth.AccessTokenTTL, cfg.Auth.RefreshTokenTTL)
+	apiKeyUseCase := usecase.NewAPIKeyUseCase(apiKeyRepo, auditUseCase)
+	testDataUseCase := usecase.NewTestDataUseCase(testDataRepo, auditUseCase)
+	callbackUseCase := usecase.NewCallbackUseCase(callbackAdapters, callbackNonceRepo, paymentUseCase)
 
 	paymentHandler := handler.NewPaymentHandler(paymentUseCase)
+	subscriptionHandler := handler.NewSubscriptionHandler(subscriptionUseCase)
+	invoiceHandler := handler.NewInvoiceHandler(invoiceUseCase)
+	receiptHandler := handler.NewReceiptHandler(receiptUseCase)
+	auditHandler := handler.NewAuditHandler(auditUseCase)
+	authHandler := handler.NewAuthHandler(authUseCase)
+	apiKeyHandler := handler.NewAPIKeyHandler(apiKeyUseCase)
+	merchantHandler := handler.NewMerchantHandler(merchantUseCase)
+	testDataHandler := handler.NewTestDataHandler(testDataUseCase)
+	callbackHandler := handler.NewCallbackHandler(callbackUseCase)
+
+	renewalScheduler := scheduler.New("subscription-renewal",
+		cfg.Jobs.SubscriptionRenewalInterval,
+		subscriptionUseCase.RenewDueSubscriptions)
+	renewalScheduler.Start()
+
+	scheduledPaymentScheduler := scheduler.New("scheduled-payments",
+		cfg.Jobs.ScheduledPaymentInterval,
+		paymentUseCase.ProcessDueScheduledPayments)
+	scheduledPaymentScheduler.Start()
+
+	revokedTokenScheduler := scheduler.New("revoked-token-purge",
+		cfg.Jobs.RevokedTokenPurgeInterval,
+		authUseCase.PurgeRevokedTokens)
+	revokedTokenScheduler.Start()
+
+	callbackNonceScheduler := scheduler.New("callback-nonce-purge",
+		cfg.Jobs.CallbackNoncePurgeInterval,
+		callbackUseCase.PurgeExpiredNonces)
+	callbackNonceScheduler.Start()
+
+	rateLimitConfig := rateLimitConfig(cfg.RateLimit)
+	unauthenticatedLimit, _ := ratelimit.ParseLimit(cfg.RateLimit.Unauthenticated)
+
+	var rateLimitStore ratelimit.Store = ratelimit.NewMemoryStore()
+	var rateLimitScheduler *scheduler.Scheduler
+	if cfg.RateLimit.Store == "postgres" {
+		postgresStore := postgres.NewRateLimitStore(db)
+		if err := postgresStore.InitTable(); err != nil {
+			logger.Fatal("Failed to init tables", "error", err)
+		}
+		rateLimitStore = postgresStore
+
+		rateLimitScheduler = scheduler.New("rate-limit-purge",
+			cfg.Jobs.RateLimitPurgeInterval,
+			func(ctx context.Context) error {
+				_, err := postgresStore.DeleteExpired(ctx, time.Now())
+				return err
+			})
+		rateLimitScheduler.Start()
+	}
+
+	healthRegistry := health.NewRegistry()
+	healthRegistry.Register("database", health.CheckerFunc(db.PingContext), cfg.Health.DatabaseTimeout)
+	healthRegistry.Register("schema", postgres.NewSchemaChecker(db,
+		"merchants", "payments", "plans", "subscriptions", "invoices", "receipts", "audit_entries",
+		"api_clients", "refresh_tokens", "revoked_tokens", "api_keys", "callback_nonces"), 0)
+	healthRegistry.Register("processor", liveProcessor, 0)
+	healthRegistry.Register("scheduler.subscription-renewal", renewalScheduler, 0)
+	healthRegistry.Register("scheduler.scheduled-payments", scheduledPaymentScheduler, 0)
+	healthRegistry.Register("scheduler.revoked-token-purge", revokedTokenScheduler, 0)
+	healthRegistry.Register("scheduler.callback-nonce-purge", callbackNonceScheduler, 0)
+	if rateLimitScheduler != nil {
+		healthRegistry.Register("scheduler.rate-limit-purge", rateLimitScheduler, 0)
+	}
+	healthHandler := handler.NewHealthHandler(healthRegistry)
+
+	corsConfig, err := corsConfig(cfg.CORS)
+	if err != nil {
+		logger.Fatal("Invalid CORS configuration", "error", err)
+	}
 
 	router := mux.NewRouter()
-	router.Use(middleware.CORS)
-	router.Use(middleware.RequestLogger)
+	router.Use(middleware.RecordRoute)
+	router.Use(middleware.RateLimitUnauthenticated(unauthenticatedLimit, rateLimitStore, "/health", "/health/", metricsPath, handler.CallbackPathPrefix))
+	router.Use(middleware.Authenticate(jwtAuth, apiKeyUseCase, "/health", "/health/", metricsPath, handler.TokenPath, handler.RevokePath, handler.CallbackPathPrefix))
+	router.Use(middleware.RateLimit(rateLimitConfig, rateLimitStore, merchantUseCase))
 
 	router.HandleFunc("/health", handler.HealthCheck).Methods(http.MethodGet)
+	healthHandler.RegisterRoutes(router)
+
+	// A metrics address serves /metrics and the log level endpoint on a
+	// separate admin listener that need not be exposed with the API. Without
+	// one the log level cannot be changed at runtime: it is process wide, and
+	// no API principal is scoped to the whole platform.
+	var metricsSrv *http.Server
+	if addr := cfg.Server.MetricsAddr; addr != "" {
+		metricsMux := mux.NewRouter()
+		metricsMux.Handle(metricsPath, metrics.Handler()).Methods(http.MethodGet)
+		handler.RegisterLogLevelRoutes(metricsMux)
+		metricsSrv = &http.Server{
+			Addr:        addr,
+			Handler:     metricsMux,
+			ReadTimeout: cfg.Server.ReadTimeout,
+		}
+		go func() {
+			logger.Info("Metrics server starting", "addr", metricsSrv.Addr)
+			if err := metricsSrv.ListenAndServe(); err != http.ErrServerClosed {
+				logger.Error("Metrics server failed", "error", err)
+			}
+		}()
+	} else {
+		router.Handle(metricsPath, metrics.Handler()).Methods(http.MethodGet)
+	}
 
 	paymentHandler.RegisterRoutes(router)
+	subscriptionHandler.RegisterRoutes(router)
+	invoiceHandler.RegisterRoutes(router)
+	receiptHandler.RegisterRoutes(router)
+	auditHandler.RegisterRoutes(router)
+	authHandler.RegisterRoutes(router)
+	apiKeyHandler.RegisterRoutes(router)
+	merchantHandler.RegisterRoutes(router)
+	testDataHandler.RegisterRoutes(router)
+	callbackHandler.RegisterRoutes(router, middleware.VerifyCallbackSignature(callbackSecrets, callbackNonceRepo,
+		cfg.Callbacks.Tolerance))
+
+	// Tracing, AccessLog and Metrics wrap the router so that they also see
+	// requests it answers without running route middleware: 404s, 405s and
+	// CORS preflights. Recover runs inside them so that panics are recorded
+	// as 500s.
+	var httpHandler http.Handler = middleware.CORS(corsConfig)(router)
+	httpHandler = middleware.Recover(middleware.LogReporter{})(httpHandler)
+	httpHandler = middleware.Metrics(httpHandler)
+	httpHandler = middleware.AccessLog(middleware.AccessLogConfig{
+		Format:        cfg.Log.AccessLogFormat,
+		SlowThreshold: cfg.Log.SlowRequestThreshold,
+		Output:        os.Stdout,
+	})(httpHandler)
+	httpHandler = middleware.Tracing(httpHandler)
+	httpHandler = middleware.RequestID(httpHandler)
 
 	srv := &http.Server{
-		Addr:         fmt.Sprintf(":%s", getEnv("PORT", "8080")),
-		Handler:      router,
-		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 15 * time.Second,
+		Addr:         fmt.Sprintf(":%d", cfg.Server.Port),
+		Handler:      httpHandler,
+		ReadTimeout:  cfg.Server.ReadTimeout,
+		WriteTimeout: cfg.Server.WriteTimeout,
 	}
 
 	go func() {
-		logger.Info("Server starting on port %s", srv.Addr)
+		logger.Info("Server starting", "addr", srv.Addr)
 		if err := srv.ListenAndServe(); err != http.ErrServerClosed {
-			logger.Error("Server failed to start: %v", err)
+			logger.Error("Server failed to start", "error", err)
 			os.Exit(1)
 		}
 	}()
@@ -79,17 +365,61 @@ func main() {
 	<-quit
 
 	logger.Info("Shutting down server...")
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	// Fail readiness first and give load balancers time to notice before
+	// the listener stops accepting connections.
+	healthRegistry.SetReady(false)
+	time.Sleep(cfg.Server.ShutdownDrainDelay)
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
 	defer cancel()
 
 	if err := srv.Shutdown(ctx); err != nil {
-		logger.Info("Server forces to shutdown: %v", err)
+		logger.Info("Server forces to shutdown", "error", err)
+	}
+	if metricsSrv != nil {
+		if err := metricsSrv.Shutdown(ctx); err != nil {
+			logger.Info("Metrics server forced to shutdown", "error", err)
+		}
+	}
+
+	renewalScheduler.Stop()
+	scheduledPaymentScheduler.Stop()
+	revokedTokenScheduler.Stop()
+	callbackNonceScheduler.Stop()
+	if rateLimitScheduler != nil {
+		rateLimitScheduler.Stop()
+	}
+
+	if err := shutdownTracing(ctx); err != nil {
+		logger.Error("Failed to flush traces", "error", err)
 	}
 }
 
-func getEnv(key, defaultValue string) string {
-	if value, exists := os.LookupEnv(key); exists {
-		return value
+const metricsPath = "/metrics"
+
+func corsConfig(cfg config.CORSConfig) (middleware.CORSConfig, error) {
+	cors := middleware.CORSConfig{
+		Default: middleware.CORSPolicy{
+			AllowedOrigins:   cfg.AllowedOrigins,
+			AllowedMethods:   cfg.AllowedMethods,
+			AllowedHeaders:   cfg.AllowedHeaders,
+			ExposedHeaders:   cfg.ExposedHeaders,
+			AllowCredentials: cfg.AllowCredentials,
+			MaxAge:           int(cfg.MaxAge.Seconds()),
+		},
 	}
-	return defaultValue
+	if cfg.Routes != "" {
+		if err := json.Unmarshal([]byte(cfg.Routes), &cors.Routes); err != nil {
+			return cors, fmt.Errorf("CORS_ROUTES: %w", err)
+		}
+	}
+	return cors, nil
+}
+
+// rateLimitConfig converts limits that config.Load has already validated.
+func rateLimitConfig(cfg config.RateLimitConfig) middleware.RateLimitConfig {
+	var limits middleware.RateLimitConfig
+	limits.Default, _ = ratelimit.ParseLimit(cfg.Default)
+	limits.Plans, _ = ratelimit.ParseLimits(cfg.Plans)
+	limits.Routes, _ = ratelimit.ParseLimits(cfg.Routes)
+	return limits
 }
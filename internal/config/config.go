@@ -0,0 +1,159 @@
+// Package config loads the server configuration.
+//
+// Every setting has a default and an environment variable name. Settings are
+// read, from lowest to highest precedence, from the defaults, a YAML file
+// (--config or CONFIG_FILE), a .env file (--env-file or ENV_FILE, default
+// ".env"), the environment and command-line flags. A flag is named after its
+// variable, e.g. DB_HOST becomes --db-host. Any variable may instead be read
+// from a file by setting <NAME>_FILE to its path, which is how container
+// platforms usually mount secrets.
+package config
+
+import "time"
+
+type Config struct {
+	Server    ServerConfig    `yaml:"server"`
+	Database  DatabaseConfig  `yaml:"database"`
+	Log       LogConfig       `yaml:"log"`
+	Tracing   TracingConfig   `yaml:"tracing"`
+	Auth      AuthConfig      `yaml:"auth"`
+	Payments  PaymentsConfig  `yaml:"payments"`
+	Invoice   InvoiceConfig   `yaml:"invoice"`
+	Callbacks CallbacksConfig `yaml:"callbacks"`
+	RateLimit RateLimitConfig `yaml:"rate_limit"`
+	CORS      CORSConfig      `yaml:"cors"`
+	Jobs      JobsConfig      `yaml:"jobs"`
+	Health    HealthConfig    `yaml:"health"`
+}
+
+type ServerConfig struct {
+	Port            int           `yaml:"port" env:"PORT" default:"8080"`
+	ReadTimeout     time.Duration `yaml:"read_timeout" env:"SERVER_READ_TIMEOUT" default:"15s"`
+	WriteTimeout    time.Duration `yaml:"write_timeout" env:"SERVER_WRITE_TIMEOUT" default:"15s"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT" default:"30s"`
+	// ShutdownDrainDelay is how long readiness fails before the listener
+	// stops accepting connections.
+	ShutdownDrainDelay time.Duration `yaml:"shutdown_drain_delay" env:"SHUTDOWN_DRAIN_DELAY" default:"0s"`
+	// MetricsAddr serves /metrics and the log level endpoint on a separate
+	// internal listener when set.
+	MetricsAddr string `yaml:"metrics_addr" env:"METRICS_ADDR"`
+}
+
+type DatabaseConfig struct {
+	// URL is a postgres:// connection URL. When set it replaces the host,
+	// port, user, password, name and SSL mode settings.
+	URL         string `yaml:"url" env:"DATABASE_URL" secret:"true"`
+	Host        string `yaml:"host" env:"DB_HOST" default:"localhost"`
+	Port        int    `yaml:"port" env:"DB_PORT" default:"5432"`
+	User        string `yaml:"user" env:"DB_USER" default:"postgres"`
+	Password    string `yaml:"password" env:"DB_PASSWORD" default:"postgres" secret:"true"`
+	Name        string `yaml:"name" env:"DB_NAME" default:"go_api"`
+	SSLMode     string `yaml:"ssl_mode" env:"DB_SSL_MODE" default:"disable"`
+	SSLRootCert string `yaml:"ssl_root_cert" env:"DB_SSL_ROOT_CERT"`
+	SSLCert     string `yaml:"ssl_cert" env:"DB_SSL_CERT"`
+	SSLKey      string `yaml:"ssl_key" env:"DB_SSL_KEY"`
+
+	ApplicationName string `yaml:"application_name" env:"DB_APPLICATION_NAME" default:"go-api"`
+	// StatementTimeout aborts queries running longer; 0s disables it.
+	StatementTimeout time.Duration `yaml:"statement_timeout" env:"DB_STATEMENT_TIMEOUT" default:"30s"`
+
+	MaxOpenConns    int           `yaml:"max_open_conns" env:"DB_MAX_OPEN_CONNS" default:"25"`
+	MaxIdleConns    int           `yaml:"max_idle_conns" env:"DB_MAX_IDLE_CONNS" default:"10"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" env:"DB_CONN_MAX_LIFETIME" default:"30m"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time" env:"DB_CONN_MAX_IDLE_TIME" default:"5m"`
+
+	// ConnectTimeout is how long startup keeps retrying while the database
+	// is unavailable, e.g. while its container boots.
+	ConnectTimeout time.Duration `yaml:"connect_timeout" env:"DB_CONNECT_TIMEOUT" default:"1m"`
+}
+
+type LogConfig struct {
+	// Level defaults to debug when Debug is set and info otherwise.
+	Level                string        `yaml:"level" env:"LOG_LEVEL"`
+	Debug                bool          `yaml:"debug" env:"DEBUG" default:"false"`
+	Format               string        `yaml:"format" env:"LOG_FORMAT" default:"json"`
+	AccessLogFormat      string        `yaml:"access_log_format" env:"ACCESS_LOG_FORMAT" default:"json"`
+	SlowRequestThreshold time.Duration `yaml:"slow_request_threshold" env:"ACCESS_LOG_SLOW_THRESHOLD" default:"1s"`
+}
+
+type TracingConfig struct {
+	// Exporter is none, stdout or otlp. The OTLP endpoint is read from
+	// OTEL_EXPORTER_OTLP_ENDPOINT.
+	Exporter    string  `yaml:"exporter" env:"TRACING_EXPORTER" default:"none"`
+	ServiceName string  `yaml:"service_name" env:"TRACING_SERVICE_NAME" default:"go-api"`
+	SampleRatio float64 `yaml:"sample_ratio" env:"TRACING_SAMPLE_RATIO" default:"1"`
+}
+
+type AuthConfig struct {
+	JWTSecret           string        `yaml:"jwt_secret" env:"JWT_SECRET" secret:"true"`
+	JWTIssuer           string        `yaml:"jwt_issuer" env:"JWT_ISSUER"`
+	JWTAudience         string        `yaml:"jwt_audience" env:"JWT_AUDIENCE"`
+	JWTLeeway           time.Duration `yaml:"jwt_leeway" env:"JWT_LEEWAY" default:"30s"`
+	JWKS                string        `yaml:"jwks" env:"JWT_JWKS"`
+	JWKSRefreshInterval time.Duration `yaml:"jwks_refresh_interval" env:"JWT_JWKS_REFRESH_INTERVAL" default:"15m"`
+	AccessTokenTTL      time.Duration `yaml:"access_token_ttl" env:"ACCESS_TOKEN_TTL" default:"15m"`
+	RefreshTokenTTL     time.Duration `yaml:"refresh_token_ttl" env:"REFRESH_TOKEN_TTL" default:"720h"`
+}
+
+// PaymentsConfig holds the platform wide limits. Merchants may narrow them
+// but not widen them.
+type PaymentsConfig struct {
+	MinAmount  int64    `yaml:"min_amount" env:"PAYMENT_MIN_AMOUNT" default:"1"`
+	MaxAmount  int64    `yaml:"max_amount" env:"PAYMENT_MAX_AMOUNT" default:"10000000"`
+	Currencies []string `yaml:"currencies" env:"PAYMENT_CURRENCIES" default:"JPY,USD"`
+}
+
+// InvoiceConfig is the invoice issuer of the default merchant until it sets
+// its own through the merchant API. Other merchants configure theirs there.
+type InvoiceConfig struct {
+	IssuerName               string `yaml:"issuer_name" env:"INVOICE_ISSUER_NAME"`
+	IssuerRegistrationNumber string `yaml:"issuer_registration_number" env:"INVOICE_ISSUER_REGISTRATION_NUMBER"`
+	IssuerAddress            string `yaml:"issuer_address" env:"INVOICE_ISSUER_ADDRESS"`
+}
+
+type CallbacksConfig struct {
+	// Secrets are comma separated provider=secret pairs.
+	Secrets   string        `yaml:"secrets" env:"CALLBACK_SECRETS" secret:"true"`
+	Tolerance time.Duration `yaml:"tolerance" env:"CALLBACK_TOLERANCE" default:"5m"`
+}
+
+// RateLimitConfig limits are written as <requests>/<period>. Plan and route
+// limits are comma separated <name>=<limit> pairs, where routes are named by
+// method and path template, e.g. "POST /api/v1/payments=60/1m".
+type RateLimitConfig struct {
+	Default string `yaml:"default" env:"RATE_LIMIT_DEFAULT" default:"600/1m"`
+	Plans   string `yaml:"plans" env:"RATE_LIMIT_PLANS"`
+	Routes  string `yaml:"routes" env:"RATE_LIMIT_ROUTES" default:"POST /api/v1/payments=60/1m"`
+	// Unauthenticated limits, per remote IP, requests without credentials
+	// and requests whose credentials are rejected.
+	Unauthenticated string `yaml:"unauthenticated" env:"RATE_LIMIT_UNAUTHENTICATED" default:"60/1m"`
+	// Store is memory or postgres. Use postgres to share limits between
+	// replicas.
+	Store string `yaml:"store" env:"RATE_LIMIT_STORE" default:"memory"`
+}
+
+// CORSConfig is the default CORS policy. No origins are allowed unless
+// AllowedOrigins is set. Routes holds per path prefix overrides as a JSON
+// object of complete policies.
+type CORSConfig struct {
+	AllowedOrigins   []string      `yaml:"allowed_origins" env:"CORS_ALLOWED_ORIGINS"`
+	AllowedMethods   []string      `yaml:"allowed_methods" env:"CORS_ALLOWED_METHODS" default:"GET,POST,PUT,DELETE"`
+	AllowedHeaders   []string      `yaml:"allowed_headers" env:"CORS_ALLOWED_HEADERS" default:"Accept,Authorization,Content-Type"`
+	ExposedHeaders   []string      `yaml:"exposed_headers" env:"CORS_EXPOSED_HEADERS" default:"RateLimit-Limit,RateLimit-Remaining,RateLimit-Reset,Retry-After,X-Request-ID"`
+	AllowCredentials bool          `yaml:"allow_credentials" env:"CORS_ALLOW_CREDENTIALS" default:"false"`
+	MaxAge           time.Duration `yaml:"max_age" env:"CORS_MAX_AGE" default:"10m"`
+	Routes           string        `yaml:"routes" env:"CORS_ROUTES"`
+}
+
+// JobsConfig holds the intervals of the background schedulers.
+type JobsConfig struct {
+	SubscriptionRenewalInterval time.Duration `yaml:"subscription_renewal_interval" env:"SUBSCRIPTION_RENEWAL_INTERVAL" default:"1m"`
+	ScheduledPaymentInterval    time.Duration `yaml:"scheduled_payment_interval" env:"SCHEDULED_PAYMENT_INTERVAL" default:"30s"`
+	RevokedTokenPurgeInterval   time.Duration `yaml:"revoked_token_purge_interval" env:"REVOKED_TOKEN_PURGE_INTERVAL" default:"1h"`
+	CallbackNoncePurgeInterval  time.Duration `yaml:"callback_nonce_purge_interval" env:"CALLBACK_NONCE_PURGE_INTERVAL" default:"1h"`
+	RateLimitPurgeInterval      time.Duration `yaml:"rate_limit_purge_interval" env:"RATE_LIMIT_PURGE_INTERVAL" default:"10m"`
+}
+
+type HealthConfig struct {
+	DatabaseTimeout time.Duration `yaml:"database_timeout" env:"HEALTH_DATABASE_TIMEOUT" default:"1s"`
+}
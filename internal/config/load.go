@@ -0,0 +1,405 @@
+package config
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Sources a setting can come from, in increasing order of precedence.
+const (
+	SourceDefault = "default"
+	SourceFile    = "file"
+	SourceDotEnv  = ".env"
+	SourceEnv     = "env"
+	SourceFlag    = "flag"
+)
+
+const defaultEnvFile = ".env"
+
+// redacted replaces the value of secret settings in Effective.
+const redacted = "[REDACTED]"
+
+// field is one setting of Config.
+type field struct {
+	env      string
+	yamlPath []string
+	def      string
+	secret   bool
+	value    reflect.Value
+}
+
+// Loaded is a configuration together with where each setting came from.
+type Loaded struct {
+	*Config
+	// PrintOnly is set by --print-config: the caller should print Effective
+	// and exit instead of starting the server.
+	PrintOnly bool
+
+	sources map[string]string
+	fields  []field
+}
+
+// Load reads the configuration from the defaults, files, environment and
+// args (usually os.Args[1:]) and validates it. flag.ErrHelp is returned when
+// args ask for usage, which has then been written to stderr.
+func Load(args []string) (*Loaded, error) {
+	return load(args, os.LookupEnv, os.Stderr)
+}
+
+func load(args []string, lookupEnv func(string) (string, bool), usage io.Writer) (*Loaded, error) {
+	cfg := &Config{}
+	l := &Loaded{
+		Config:  cfg,
+		sources: make(map[string]string),
+		fields:  collectFields(reflect.ValueOf(cfg).Elem(), nil),
+	}
+
+	opts, err := parseFlags(l.fields, args, usage)
+	if err != nil {
+		return nil, err
+	}
+	flags, configFile, envFile := opts.values, opts.configFile, opts.envFile
+	l.PrintOnly = opts.printOnly
+
+	for _, f := range l.fields {
+		if err := l.set(f, f.def, SourceDefault); err != nil {
+			return nil, err
+		}
+	}
+
+	if configFile == "" {
+		configFile, _ = lookupEnv("CONFIG_FILE")
+	}
+	if configFile != "" {
+		if err := l.loadFile(configFile); err != nil {
+			return nil, err
+		}
+	}
+
+	explicitEnvFile := envFile != ""
+	if !explicitEnvFile {
+		envFile, explicitEnvFile = lookupEnv("ENV_FILE")
+	}
+	if !explicitEnvFile {
+		envFile = defaultEnvFile
+	}
+	dotEnv, err := readDotEnv(envFile)
+	if err != nil && (explicitEnvFile || !errors.Is(err, os.ErrNotExist)) {
+		return nil, err
+	}
+
+	var errs []error
+	for _, f := range l.fields {
+		if err := l.loadVariable(f, SourceDotEnv, mapLookup(dotEnv)); err != nil {
+			errs = append(errs, err)
+		}
+		if err := l.loadVariable(f, SourceEnv, lookupEnv); err != nil {
+			errs = append(errs, err)
+		}
+		if value, ok := flags[f.env]; ok {
+			if err := l.set(f, value, SourceFlag); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// collectFields lists the settings of the struct v, depth first.
+func collectFields(v reflect.Value, path []string) []field {
+	var fields []field
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		name, _, _ := strings.Cut(sf.Tag.Get("yaml"), ",")
+		fieldPath := append(append([]string(nil), path...), name)
+
+		if sf.Type.Kind() == reflect.Struct && sf.Type != reflect.TypeOf(time.Duration(0)) {
+			fields = append(fields, collectFields(v.Field(i), fieldPath)...)
+			continue
+		}
+		fields = append(fields, field{
+			env:      sf.Tag.Get("env"),
+			yamlPath: fieldPath,
+			def:      sf.Tag.Get("default"),
+			secret:   sf.Tag.Get("secret") == "true",
+			value:    v.Field(i),
+		})
+	}
+	return fields
+}
+
+func (l *Loaded) set(f field, value, source string) error {
+	if err := setValue(f.value, value); err != nil {
+		return fmt.Errorf("%s (from %s): %w", f.env, source, err)
+	}
+	l.sources[f.env] = source
+	return nil
+}
+
+// loadVariable sets f from NAME, or from the file named by NAME_FILE.
+func (l *Loaded) loadVariable(f field, source string, lookup func(string) (string, bool)) error {
+	value, ok := lookup(f.env)
+	path, fromFile := lookup(f.env + "_FILE")
+	switch {
+	case ok && fromFile:
+		return fmt.Errorf("%s (from %s): set both %s and %s_FILE", f.env, source, f.env, f.env)
+	case fromFile:
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("%s_FILE (from %s): %w", f.env, source, err)
+		}
+		return l.set(f, strings.TrimRight(string(b), "\r\n"), source)
+	case ok:
+		return l.set(f, value, source)
+	}
+	return nil
+}
+
+func mapLookup(m map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		value, ok := m[key]
+		return value, ok
+	}
+}
+
+// setValue parses s into v. Lists are comma separated.
+func setValue(v reflect.Value, s string) error {
+	s = strings.TrimSpace(s)
+	if v.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q, use a value such as 30s or 5m", s)
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q, use true or false", s)
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", s)
+		}
+		v.SetInt(n)
+	case reflect.Float64:
+		n, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", s)
+		}
+		v.SetFloat(n)
+	case reflect.Slice:
+		var values []string
+		for _, item := range strings.Split(s, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				values = append(values, item)
+			}
+		}
+		v.Set(reflect.ValueOf(values))
+	default:
+		return fmt.Errorf("unsupported setting type %s", v.Type())
+	}
+	return nil
+}
+
+// loadFile applies a YAML file whose keys mirror the yaml tags of Config.
+// Unknown keys are rejected so that typos do not go unnoticed.
+func (l *Loaded) loadFile(path string) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("config file: %w", err)
+	}
+	var doc map[string]any
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return fmt.Errorf("config file %s: %w", path, err)
+	}
+
+	byPath := make(map[string]field, len(l.fields))
+	for _, f := range l.fields {
+		byPath[strings.Join(f.yamlPath, ".")] = f
+	}
+
+	var errs []error
+	var walk func(prefix string, node map[string]any)
+	walk = func(prefix string, node map[string]any) {
+		for key, value := range node {
+			name := prefix + key
+			if nested, ok := value.(map[string]any); ok {
+				walk(name+".", nested)
+				continue
+			}
+			f, ok := byPath[name]
+			if !ok {
+				errs = append(errs, fmt.Errorf("config file %s: unknown setting %s", path, name))
+				continue
+			}
+			if err := l.set(f, yamlScalar(value), SourceFile); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	walk("", doc)
+	return errors.Join(errs...)
+}
+
+func yamlScalar(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case []any:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = fmt.Sprint(item)
+		}
+		return strings.Join(items, ",")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// readDotEnv parses NAME=value lines. Blank lines, comments and an export
+// prefix are allowed, and values may be quoted.
+func readDotEnv(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("env file: %w", err)
+	}
+	defer file.Close()
+
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		key, value, found := strings.Cut(line, "=")
+		if !found {
+			return nil, fmt.Errorf("env file %s:%d: expected NAME=value", path, n)
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		values[strings.TrimSpace(key)] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("env file %s: %w", path, err)
+	}
+	return values, nil
+}
+
+// flagValue records a flag without parsing it, so that flags can be applied
+// after the other sources.
+type flagValue struct {
+	env    string
+	isBool bool
+	values map[string]string
+}
+
+func (v *flagValue) String() string { return "" }
+
+func (v *flagValue) Set(s string) error {
+	v.values[v.env] = s
+	return nil
+}
+
+func (v *flagValue) IsBoolFlag() bool { return v.isBool }
+
+type flagOptions struct {
+	values     map[string]string
+	configFile string
+	envFile    string
+	printOnly  bool
+}
+
+func parseFlags(fields []field, args []string, usage io.Writer) (flagOptions, error) {
+	fs := flag.NewFlagSet("server", flag.ContinueOnError)
+	fs.SetOutput(usage)
+
+	var opts flagOptions
+	fs.StringVar(&opts.configFile, "config", "", "YAML configuration `file` (CONFIG_FILE)")
+	fs.StringVar(&opts.envFile, "env-file", "", "env `file` to load (ENV_FILE, default .env)")
+	fs.BoolVar(&opts.printOnly, "print-config", false, "print the effective configuration and exit")
+
+	values := make(map[string]string)
+	for _, f := range fields {
+		description := f.env
+		if f.def != "" {
+			description += " (default " + f.def + ")"
+		}
+		fs.Var(&flagValue{env: f.env, isBool: f.value.Kind() == reflect.Bool, values: values}, FlagName(f.env), description)
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return opts, err
+	}
+	if fs.NArg() > 0 {
+		return opts, fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+	opts.values = values
+	return opts, nil
+}
+
+// FlagName is the command-line flag for the environment variable env.
+func FlagName(env string) string {
+	return strings.ReplaceAll(strings.ToLower(env), "_", "-")
+}
+
+// Setting is one entry of the effective configuration.
+type Setting struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Source string `json:"source"`
+}
+
+// Effective lists every setting with its value and source. Secrets are
+// redacted.
+func (l *Loaded) Effective() []Setting {
+	settings := make([]Setting, 0, len(l.fields))
+	for _, f := range l.fields {
+		value := formatValue(f.value)
+		if f.secret && value != "" {
+			value = redacted
+		}
+		settings = append(settings, Setting{Name: f.env, Value: value, Source: l.sources[f.env]})
+	}
+	return settings
+}
+
+func formatValue(v reflect.Value) string {
+	switch {
+	case v.Type() == reflect.TypeOf(time.Duration(0)):
+		return time.Duration(v.Int()).String()
+	case v.Kind() == reflect.Slice:
+		return strings.Join(v.Interface().([]string), ",")
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+}
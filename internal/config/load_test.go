@@ -0,0 +1,264 @@
+package config
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testJWTSecret = "k3vQ9zR1xW7mN2pL5tY8bC4fH6jD0sAe"
+
+// testLoad loads args with env as the whole environment. JWT_SECRET, which
+// has no default, is filled in unless env sets it or its _FILE variant, and
+// ENV_FILE points at an empty file unless env or args name one, so that no
+// .env in the working directory leaks into the test.
+func testLoad(t *testing.T, env map[string]string, args ...string) (*Loaded, error) {
+	t.Helper()
+	_, secret := env["JWT_SECRET"]
+	_, secretFile := env["JWT_SECRET_FILE"]
+	if !secret && !secretFile {
+		env["JWT_SECRET"] = testJWTSecret
+	}
+	if _, ok := env["ENV_FILE"]; !ok && !hasFlag(args, "--env-file") {
+		env["ENV_FILE"] = writeFile(t, ".env", "")
+	}
+	return load(args, mapLookup(env), io.Discard)
+}
+
+func mustLoad(t *testing.T, env map[string]string, args ...string) *Loaded {
+	t.Helper()
+	l, err := testLoad(t, env, args...)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	return l
+}
+
+func hasFlag(args []string, name string) bool {
+	for _, arg := range args {
+		if arg == name || strings.HasPrefix(arg, name+"=") {
+			return true
+		}
+	}
+	return false
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func assertSource(t *testing.T, l *Loaded, name, want string) {
+	t.Helper()
+	if got := l.sources[name]; got != want {
+		t.Errorf("source of %s = %q, want %q", name, got, want)
+	}
+}
+
+func assertErrorContains(t *testing.T, err error, parts ...string) {
+	t.Helper()
+	if err == nil {
+		t.Fatalf("got no error, want one containing %q", parts)
+	}
+	for _, part := range parts {
+		if !strings.Contains(err.Error(), part) {
+			t.Errorf("error %q does not contain %q", err, part)
+		}
+	}
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	configFile := writeFile(t, "config.yaml", `
+server:
+  port: 8001
+database:
+  host: yaml-host
+  name: yaml-name
+  user: yaml-user
+`)
+	envFile := writeFile(t, ".env", `
+# comment
+PORT=8002
+DB_NAME="dotenv-name"
+export DB_USER=dotenv-user
+`)
+	env := map[string]string{
+		"JWT_SECRET":  testJWTSecret,
+		"CONFIG_FILE": configFile,
+		"ENV_FILE":    envFile,
+		"PORT":        "8003",
+		"DB_USER":     "env-user",
+	}
+
+	l := mustLoad(t, env, "--port", "8004")
+
+	if l.Database.Password != "postgres" {
+		t.Errorf("DB_PASSWORD = %q, want the default", l.Database.Password)
+	}
+	assertSource(t, l, "DB_PASSWORD", SourceDefault)
+	if l.Database.Host != "yaml-host" {
+		t.Errorf("DB_HOST = %q, want the YAML value", l.Database.Host)
+	}
+	assertSource(t, l, "DB_HOST", SourceFile)
+	if l.Database.Name != "dotenv-name" {
+		t.Errorf("DB_NAME = %q, want the .env value", l.Database.Name)
+	}
+	assertSource(t, l, "DB_NAME", SourceDotEnv)
+	if l.Database.User != "env-user" {
+		t.Errorf("DB_USER = %q, want the environment value", l.Database.User)
+	}
+	assertSource(t, l, "DB_USER", SourceEnv)
+	if l.Server.Port != 8004 {
+		t.Errorf("PORT = %d, want the flag value", l.Server.Port)
+	}
+	assertSource(t, l, "PORT", SourceFlag)
+}
+
+func TestLoadFlagsNameFiles(t *testing.T) {
+	configFile := writeFile(t, "config.yaml", "database:\n  host: flag-file-host\n")
+	envFile := writeFile(t, ".env", "DB_NAME=flag-env-name\n")
+
+	l := mustLoad(t, map[string]string{
+		"JWT_SECRET":  testJWTSecret,
+		"CONFIG_FILE": writeFile(t, "ignored.yaml", "database:\n  host: ignored\n"),
+		"ENV_FILE":    writeFile(t, "ignored.env", "DB_NAME=ignored\n"),
+	}, "--config="+configFile, "--env-file="+envFile)
+
+	if l.Database.Host != "flag-file-host" || l.Database.Name != "flag-env-name" {
+		t.Errorf("DB_HOST, DB_NAME = %q, %q, want the files named by flags", l.Database.Host, l.Database.Name)
+	}
+}
+
+func TestLoadFileVariants(t *testing.T) {
+	secretFile := writeFile(t, "jwt_secret", testJWTSecret+"\n")
+	passwordFile := writeFile(t, "db_password", "from-file\r\n")
+	envFile := writeFile(t, ".env", "DB_PASSWORD_FILE="+passwordFile+"\n")
+
+	l := mustLoad(t, map[string]string{
+		"JWT_SECRET_FILE": secretFile,
+		"ENV_FILE":        envFile,
+	})
+
+	if l.Auth.JWTSecret != testJWTSecret {
+		t.Errorf("JWT_SECRET = %q, want the file contents without the newline", l.Auth.JWTSecret)
+	}
+	assertSource(t, l, "JWT_SECRET", SourceEnv)
+	if l.Database.Password != "from-file" {
+		t.Errorf("DB_PASSWORD = %q, want the file contents without the newline", l.Database.Password)
+	}
+	assertSource(t, l, "DB_PASSWORD", SourceDotEnv)
+}
+
+func TestLoadFileVariantConflicts(t *testing.T) {
+	_, err := testLoad(t, map[string]string{
+		"JWT_SECRET":      testJWTSecret,
+		"JWT_SECRET_FILE": writeFile(t, "jwt_secret", testJWTSecret),
+		"ENV_FILE":        writeFile(t, ".env", ""),
+	})
+	assertErrorContains(t, err, "set both JWT_SECRET and JWT_SECRET_FILE")
+}
+
+func TestLoadFileVariantMissingFile(t *testing.T) {
+	_, err := testLoad(t, map[string]string{
+		"DB_PASSWORD_FILE": filepath.Join(t.TempDir(), "missing"),
+	})
+	assertErrorContains(t, err, "DB_PASSWORD_FILE (from env)")
+}
+
+func TestLoadRejectsUnknownYAMLKeys(t *testing.T) {
+	configFile := writeFile(t, "config.yaml", `
+server:
+  prot: 8080
+databse:
+  host: db
+`)
+	_, err := testLoad(t, map[string]string{"CONFIG_FILE": configFile})
+	assertErrorContains(t, err, "unknown setting server.prot", "unknown setting databse.host")
+}
+
+func TestLoadMissingEnvFile(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing.env")
+
+	t.Run("flag", func(t *testing.T) {
+		_, err := testLoad(t, map[string]string{"JWT_SECRET": testJWTSecret}, "--env-file", missing)
+		assertErrorContains(t, err, "env file", "missing.env")
+	})
+	t.Run("variable", func(t *testing.T) {
+		_, err := testLoad(t, map[string]string{"JWT_SECRET": testJWTSecret, "ENV_FILE": missing})
+		assertErrorContains(t, err, "env file", "missing.env")
+	})
+	t.Run("default", func(t *testing.T) {
+		wd, err := os.Getwd()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chdir(t.TempDir()); err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { os.Chdir(wd) })
+
+		if _, err := load(nil, mapLookup(map[string]string{"JWT_SECRET": testJWTSecret}), io.Discard); err != nil {
+			t.Errorf("load without .env: %v", err)
+		}
+	})
+}
+
+func TestLoadCollectsParseErrors(t *testing.T) {
+	_, err := testLoad(t, map[string]string{
+		"PORT":               "eighty",
+		"DB_CONNECT_TIMEOUT": "5",
+	})
+	assertErrorContains(t, err, `PORT (from env): invalid integer "eighty"`, `DB_CONNECT_TIMEOUT (from env): invalid duration "5"`)
+}
+
+func TestEffectiveRedactsSecrets(t *testing.T) {
+	l := mustLoad(t, map[string]string{
+		"DATABASE_URL": "postgres://app:hunter2@db/app",
+		"DB_HOST":      "db.internal",
+	})
+
+	settings := make(map[string]Setting)
+	for _, s := range l.Effective() {
+		settings[s.Name] = s
+	}
+	for _, name := range []string{"JWT_SECRET", "DATABASE_URL", "DB_PASSWORD"} {
+		if got := settings[name].Value; got != redacted {
+			t.Errorf("%s = %q, want it redacted", name, got)
+		}
+	}
+	if got := settings["CALLBACK_SECRETS"].Value; got != "" {
+		t.Errorf("unset CALLBACK_SECRETS = %q, want it empty", got)
+	}
+	if got := settings["DB_HOST"]; got.Value != "db.internal" || got.Source != SourceEnv {
+		t.Errorf("DB_HOST = %+v, want db.internal from env", got)
+	}
+	if got := settings["SHUTDOWN_TIMEOUT"]; got.Value != "30s" || got.Source != SourceDefault {
+		t.Errorf("SHUTDOWN_TIMEOUT = %+v, want the 30s default", got)
+	}
+}
+
+func TestValidateReportsEveryError(t *testing.T) {
+	_, err := testLoad(t, map[string]string{
+		"JWT_SECRET":             "changeme",
+		"PORT":                   "0",
+		"LOG_FORMAT":             "xml",
+		"RATE_LIMIT_DEFAULT":     "fast",
+		"CORS_ALLOWED_ORIGINS":   "*",
+		"CORS_ALLOW_CREDENTIALS": "true",
+	})
+	assertErrorContains(t, err,
+		"invalid configuration",
+		"PORT must be between 1 and 65535",
+		"LOG_FORMAT must be one of json, text",
+		"RATE_LIMIT_DEFAULT",
+		"JWT_SECRET is a placeholder value",
+		"JWT_SECRET must be at least 32 characters",
+		"CORS_ALLOWED_ORIGINS must not contain *",
+	)
+}
@@ -0,0 +1,228 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"GO-API/internal/pkg/logger"
+	"GO-API/internal/pkg/ratelimit"
+	"GO-API/internal/pkg/tracing"
+)
+
+var currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// MinJWTSecretLength is the shortest HMAC secret accepted, 256 bits for
+// HS256.
+const MinJWTSecretLength = 32
+
+// placeholderSecrets are values copied from examples that must never sign
+// tokens in a running server. Values containing "change" and "me" in the
+// usual spellings are rejected as well.
+var placeholderSecrets = []string{"secret", "jwt-secret", "jwt_secret", "your-secret-key", "your-256-bit-secret", "dev-secret", "test-secret"}
+
+// sslModes are the sslmode values lib/pq accepts.
+var sslModes = []string{"disable", "require", "verify-ca", "verify-full"}
+
+// Validate reports every invalid setting at once, named by its environment
+// variable.
+func (c *Config) Validate() error {
+	v := &validator{}
+
+	v.check(c.Server.Port >= 1 && c.Server.Port <= 65535, "PORT", "must be between 1 and 65535")
+	v.positive("SERVER_READ_TIMEOUT", c.Server.ReadTimeout)
+	v.positive("SERVER_WRITE_TIMEOUT", c.Server.WriteTimeout)
+	v.positive("SHUTDOWN_TIMEOUT", c.Server.ShutdownTimeout)
+	v.check(c.Server.ShutdownDrainDelay >= 0, "SHUTDOWN_DRAIN_DELAY", "must not be negative")
+
+	if c.Database.URL != "" {
+		u, err := url.Parse(c.Database.URL)
+		// Never echo the URL or the parse error, they may contain the
+		// password.
+		v.check(err == nil && (u.Scheme == "postgres" || u.Scheme == "postgresql"),
+			"DATABASE_URL", "must be a postgres:// or postgresql:// URL")
+	} else {
+		v.check(c.Database.Host != "", "DB_HOST", "is required")
+		v.check(c.Database.Port >= 1 && c.Database.Port <= 65535, "DB_PORT", "must be between 1 and 65535")
+		v.check(c.Database.User != "", "DB_USER", "is required")
+		v.check(c.Database.Name != "", "DB_NAME", "is required")
+		v.oneOf("DB_SSL_MODE", c.Database.SSLMode, sslModes)
+	}
+	v.check((c.Database.SSLCert == "") == (c.Database.SSLKey == ""), "DB_SSL_CERT", "and DB_SSL_KEY must be set together")
+	v.check(c.Database.StatementTimeout >= 0, "DB_STATEMENT_TIMEOUT", "must not be negative")
+	v.check(c.Database.MaxOpenConns >= 0, "DB_MAX_OPEN_CONNS", "must not be negative, 0 means unlimited")
+	v.check(c.Database.MaxIdleConns >= 0, "DB_MAX_IDLE_CONNS", "must not be negative")
+	v.check(c.Database.MaxOpenConns == 0 || c.Database.MaxIdleConns <= c.Database.MaxOpenConns,
+		"DB_MAX_IDLE_CONNS", "must not exceed DB_MAX_OPEN_CONNS")
+	v.check(c.Database.ConnMaxLifetime >= 0, "DB_CONN_MAX_LIFETIME", "must not be negative, 0 means unlimited")
+	v.check(c.Database.ConnMaxIdleTime >= 0, "DB_CONN_MAX_IDLE_TIME", "must not be negative, 0 means unlimited")
+	v.check(c.Database.ConnectTimeout >= 0, "DB_CONNECT_TIMEOUT", "must not be negative")
+
+	if c.Log.Level != "" {
+		_, err := logger.ParseLevel(c.Log.Level)
+		v.check(err == nil, "LOG_LEVEL", "must be debug, info, warn or error")
+	}
+	v.oneOf("LOG_FORMAT", c.Log.Format, []string{logger.FormatJSON, logger.FormatText})
+	v.oneOf("ACCESS_LOG_FORMAT", c.Log.AccessLogFormat, []string{"json", "combined"})
+	v.positive("ACCESS_LOG_SLOW_THRESHOLD", c.Log.SlowRequestThreshold)
+
+	v.oneOf("TRACING_EXPORTER", c.Tracing.Exporter, []string{tracing.ExporterNone, tracing.ExporterStdout, tracing.ExporterOTLP})
+	v.check(c.Tracing.ServiceName != "", "TRACING_SERVICE_NAME", "is required")
+	v.check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "TRACING_SAMPLE_RATIO", "must be between 0 and 1")
+
+	v.check(c.Auth.JWTSecret != "" || c.Auth.JWKS != "", "JWT_SECRET", "or JWT_JWKS is required")
+	if c.Auth.JWTSecret != "" {
+		v.check(!placeholderSecret(c.Auth.JWTSecret), "JWT_SECRET", "is a placeholder value, generate a random secret")
+		v.check(len(c.Auth.JWTSecret) >= MinJWTSecretLength, "JWT_SECRET", fmt.Sprintf("must be at least %d characters", MinJWTSecretLength))
+	}
+	v.check(c.Auth.JWTLeeway >= 0, "JWT_LEEWAY", "must not be negative")
+	v.positive("JWT_JWKS_REFRESH_INTERVAL", c.Auth.JWKSRefreshInterval)
+	v.positive("ACCESS_TOKEN_TTL", c.Auth.AccessTokenTTL)
+	v.positive("REFRESH_TOKEN_TTL", c.Auth.RefreshTokenTTL)
+
+	v.check(c.Payments.MinAmount >= 1, "PAYMENT_MIN_AMOUNT", "must be at least 1")
+	v.check(c.Payments.MaxAmount >= c.Payments.MinAmount, "PAYMENT_MAX_AMOUNT", "must not be less than PAYMENT_MIN_AMOUNT")
+	v.check(len(c.Payments.Currencies) > 0, "PAYMENT_CURRENCIES", "must list at least one currency")
+	for _, currency := range c.Payments.Currencies {
+		v.check(currencyPattern.MatchString(currency), "PAYMENT_CURRENCIES", fmt.Sprintf("%q is not an ISO 4217 currency code", currency))
+	}
+
+	if _, err := c.Callbacks.SecretMap(); err != nil {
+		v.add("CALLBACK_SECRETS", err.Error())
+	}
+	v.positive("CALLBACK_TOLERANCE", c.Callbacks.Tolerance)
+
+	if _, err := ratelimit.ParseLimit(c.RateLimit.Default); err != nil {
+		v.add("RATE_LIMIT_DEFAULT", err.Error())
+	}
+	if _, err := ratelimit.ParseLimits(c.RateLimit.Plans); err != nil {
+		v.add("RATE_LIMIT_PLANS", err.Error())
+	}
+	if _, err := ratelimit.ParseLimits(c.RateLimit.Routes); err != nil {
+		v.add("RATE_LIMIT_ROUTES", err.Error())
+	}
+	if _, err := ratelimit.ParseLimit(c.RateLimit.Unauthenticated); err != nil {
+		v.add("RATE_LIMIT_UNAUTHENTICATED", err.Error())
+	}
+	v.oneOf("RATE_LIMIT_STORE", c.RateLimit.Store, []string{"memory", "postgres"})
+
+	v.check(c.CORS.MaxAge >= 0, "CORS_MAX_AGE", "must not be negative")
+	v.check(!c.CORS.AllowCredentials || !contains(c.CORS.AllowedOrigins, "*"),
+		"CORS_ALLOWED_ORIGINS", "must not contain * when CORS_ALLOW_CREDENTIALS is true")
+	if c.CORS.Routes != "" {
+		var routes map[string]struct {
+			AllowedOrigins   []string `json:"allowed_origins"`
+			AllowCredentials bool     `json:"allow_credentials"`
+		}
+		if err := json.Unmarshal([]byte(c.CORS.Routes), &routes); err != nil {
+			v.add("CORS_ROUTES", "must be a JSON object of policies by path prefix: "+err.Error())
+		}
+		for prefix, route := range routes {
+			v.check(!route.AllowCredentials || !contains(route.AllowedOrigins, "*"),
+				"CORS_ROUTES", fmt.Sprintf("policy for %q must not allow origin * with allow_credentials", prefix))
+		}
+	}
+
+	v.positive("SUBSCRIPTION_RENEWAL_INTERVAL", c.Jobs.SubscriptionRenewalInterval)
+	v.positive("SCHEDULED_PAYMENT_INTERVAL", c.Jobs.ScheduledPaymentInterval)
+	v.positive("REVOKED_TOKEN_PURGE_INTERVAL", c.Jobs.RevokedTokenPurgeInterval)
+	v.positive("CALLBACK_NONCE_PURGE_INTERVAL", c.Jobs.CallbackNoncePurgeInterval)
+	v.positive("RATE_LIMIT_PURGE_INTERVAL", c.Jobs.RateLimitPurgeInterval)
+
+	v.positive("HEALTH_DATABASE_TIMEOUT", c.Health.DatabaseTimeout)
+
+	return v.err()
+}
+
+// LogLevel is the configured level, defaulting to debug when DEBUG is set.
+func (c LogConfig) LogLevel() slog.Level {
+	if c.Level == "" {
+		if c.Debug {
+			return slog.LevelDebug
+		}
+		return slog.LevelInfo
+	}
+	level, _ := logger.ParseLevel(c.Level)
+	return level
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func placeholderSecret(secret string) bool {
+	lower := strings.ToLower(secret)
+	for _, marker := range []string{"changeme", "change-me", "change_me", "change-this", "change_this"} {
+		if strings.Contains(lower, marker) {
+			return true
+		}
+	}
+	for _, placeholder := range placeholderSecrets {
+		if lower == placeholder {
+			return true
+		}
+	}
+	return false
+}
+
+// SecretMap parses Secrets into callback secrets by provider.
+func (c CallbacksConfig) SecretMap() (map[string]string, error) {
+	secrets := make(map[string]string)
+	for _, entry := range strings.Split(c.Secrets, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		provider, secret, found := strings.Cut(entry, "=")
+		if !found || provider == "" || secret == "" {
+			// Never echo the entry, it may contain a secret.
+			return nil, fmt.Errorf("entries must be provider=secret pairs")
+		}
+		secrets[provider] = secret
+	}
+	return secrets, nil
+}
+
+type validator struct {
+	errs []error
+}
+
+func (v *validator) add(name, problem string) {
+	v.errs = append(v.errs, fmt.Errorf("%s %s", name, problem))
+}
+
+func (v *validator) check(ok bool, name, problem string) {
+	if !ok {
+		v.add(name, problem)
+	}
+}
+
+func (v *validator) positive(name string, d time.Duration) {
+	v.check(d > 0, name, "must be positive")
+}
+
+func (v *validator) oneOf(name, value string, allowed []string) {
+	for _, a := range allowed {
+		if value == a {
+			return
+		}
+	}
+	v.add(name, fmt.Sprintf("must be one of %s, got %q", strings.Join(allowed, ", "), value))
+}
+
+func (v *validator) err() error {
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("invalid configuration:\n%w", errors.Join(v.errs...))
+}
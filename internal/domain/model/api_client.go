@@ -0,0 +1,34 @@
+package model
+
+import "time"
+
+// APIClient is an internal tool registered for the client-credentials grant.
+// Only a hash of the client secret is stored.
+type APIClient struct {
+	ID         string     `json:"id"`
+	MerchantID string     `json:"merchant_id"`
+	Name       string     `json:"name"`
+	Role       string     `json:"role"`
+	Livemode   bool       `json:"livemode"`
+	SecretHash string     `json:"-"`
+	CreatedAt  time.Time  `json:"created_at"`
+	DisabledAt *time.Time `json:"disabled_at,omitempty"`
+}
+
+// RefreshToken is the server-side record of an issued refresh token. Tokens
+// rotated from the same original share a FamilyID so that reuse of a spent
+// token can revoke the whole chain.
+type RefreshToken struct {
+	ID        string
+	FamilyID  string
+	ClientID  string
+	TokenHash string
+	ExpiresAt time.Time
+	CreatedAt time.Time
+	UsedAt    *time.Time
+	RevokedAt *time.Time
+}
+
+func (t *RefreshToken) Active(now time.Time) bool {
+	return t.UsedAt == nil && t.RevokedAt == nil && now.Before(t.ExpiresAt)
+}
@@ -0,0 +1,50 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+const (
+	APIKeyPrefixLive = "sk_live_"
+	APIKeyPrefixTest = "sk_test_"
+)
+
+// APIKey is a long-lived secret key used by a merchant's servers. Only a hash
+// of the key is stored; Last4 lets the merchant recognise it.
+type APIKey struct {
+	ID         string     `json:"id"`
+	MerchantID string     `json:"merchant_id"`
+	Name       string     `json:"name"`
+	Livemode   bool       `json:"livemode"`
+	Last4      string     `json:"last4"`
+	KeyHash    string     `json:"-"`
+	Scopes     []string   `json:"scopes"`
+	AllowedIPs []string   `json:"allowed_ips"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+func (k *APIKey) Prefix() string {
+	if k.Livemode {
+		return APIKeyPrefixLive
+	}
+	return APIKeyPrefixTest
+}
+
+// Active reports whether the key may still authenticate requests. A rotated
+// key stays active until its ExpiresAt grace period ends.
+func (k *APIKey) Active(now time.Time) bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+	return k.ExpiresAt == nil || now.Before(*k.ExpiresAt)
+}
+
+// IsAPIKey reports whether token has the shape of a secret API key rather
+// than a JWT.
+func IsAPIKey(token string) bool {
+	return strings.HasPrefix(token, APIKeyPrefixLive) || strings.HasPrefix(token, APIKeyPrefixTest)
+}
@@ -0,0 +1,15 @@
+package model
+
+import "time"
+
+const SystemActorID = "system"
+
+type AuditEntry struct {
+	ID           string    `json:"id"`
+	MerchantID   string    `json:"merchant_id"`
+	ActorID      string    `json:"actor_id"`
+	Action       string    `json:"action"`
+	ResourceType string    `json:"resource_type"`
+	ResourceID   string    `json:"resource_id"`
+	CreatedAt    time.Time `json:"created_at"`
+}
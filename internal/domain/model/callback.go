@@ -0,0 +1,11 @@
+package model
+
+import "time"
+
+// PaymentCallback is a status notification sent asynchronously by a payment
+// processor, e.g. when a convenience store payment has been paid.
+type PaymentCallback struct {
+	TransactionID string        `json:"transaction_id"`
+	Status        PaymentStatus `json:"status"`
+	OccurredAt    time.Time     `json:"occurred_at"`
+}
@@ -5,9 +5,11 @@ import "fmt"
 type ErrorType string
 
 const (
-	ErrorTypeValidation = "validation"
-	ErrorTypeNotFound   = "not_found"
-	ErrorTypeInternal   = "internal"
+	ErrorTypeValidation   = "validation"
+	ErrorTypeNotFound     = "not_found"
+	ErrorTypeUnauthorized = "unauthorized"
+	ErrorTypeForbidden    = "forbidden"
+	ErrorTypeInternal     = "internal"
 )
 
 type Error struct {
@@ -45,3 +47,17 @@ func NewInternalError(err error) *Error {
 		Err:     err,
 	}
 }
+
+func NewUnauthorizedError(message string) *Error {
+	return &Error{
+		Type:    ErrorTypeUnauthorized,
+		Message: message,
+	}
+}
+
+func NewForbiddenError(message string) *Error {
+	return &Error{
+		Type:    ErrorTypeForbidden,
+		Message: message,
+	}
+}
@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+type InstallmentType string
+
+const (
+	InstallmentTypeLumpSum      InstallmentType = "lump_sum"
+	InstallmentTypeInstallments InstallmentType = "installments"
+	InstallmentTypeBonus        InstallmentType = "bonus"
+	InstallmentTypeRevolving    InstallmentType = "revolving"
+)
+
+type Installment struct {
+	Type     InstallmentType       `json:"type"`
+	Count    int                   `json:"count,omitempty"`
+	Schedule []InstallmentSchedule `json:"schedule,omitempty"`
+}
+
+// InstallmentSchedule is one expected charge to the cardholder. Actual
+// billing dates depend on the card issuer's closing day, so DueDate is an
+// estimate of the month the charge falls in.
+type InstallmentSchedule struct {
+	Number  int       `json:"number"`
+	DueDate time.Time `json:"due_date"`
+	Amount  int64     `json:"amount"`
+}
@@ -0,0 +1,73 @@
+package model
+
+import "time"
+
+type InvoiceStatus string
+
+const (
+	InvoiceStatusOpen InvoiceStatus = "open"
+	InvoiceStatusPaid InvoiceStatus = "paid"
+)
+
+// TaxRate is a Japanese consumption tax rate in percent.
+type TaxRate int
+
+const (
+	TaxRateStandard TaxRate = 10
+	TaxRateReduced  TaxRate = 8
+)
+
+type TaxRounding string
+
+const (
+	TaxRoundingFloor   TaxRounding = "floor"
+	TaxRoundingHalfUp  TaxRounding = "half_up"
+	TaxRoundingCeiling TaxRounding = "ceiling"
+)
+
+type InvoiceIssuer struct {
+	Name               string `json:"name"`
+	RegistrationNumber string `json:"registration_number"`
+	Address            string `json:"address,omitempty"`
+}
+
+type InvoiceLineItem struct {
+	Description string  `json:"description"`
+	Quantity    int64   `json:"quantity"`
+	UnitPrice   int64   `json:"unit_price"`
+	TaxRate     TaxRate `json:"tax_rate"`
+	Amount      int64   `json:"amount"`
+}
+
+// InvoiceTaxSummary holds the per-rate totals required on a qualified
+// invoice. Tax is rounded once per rate, never per line item.
+type InvoiceTaxSummary struct {
+	TaxRate       TaxRate `json:"tax_rate"`
+	TaxableAmount int64   `json:"taxable_amount"`
+	TaxAmount     int64   `json:"tax_amount"`
+}
+
+type Invoice struct {
+	ID            string              `json:"id"`
+	MerchantID    string              `json:"merchant_id"`
+	Livemode      bool                `json:"livemode"`
+	InvoiceNumber string              `json:"invoice_number"`
+	Issuer        InvoiceIssuer       `json:"issuer"`
+	CustomerID    string              `json:"customer_id" log:"hash"`
+	CustomerName  string              `json:"customer_name" log:"mask"`
+	Currency      string              `json:"currency"`
+	Status        InvoiceStatus       `json:"status"`
+	TaxInclusive  bool                `json:"tax_inclusive"`
+	TaxRounding   TaxRounding         `json:"tax_rounding"`
+	LineItems     []InvoiceLineItem   `json:"line_items"`
+	TaxSummaries  []InvoiceTaxSummary `json:"tax_summaries"`
+	Subtotal      int64               `json:"subtotal"`
+	TaxTotal      int64               `json:"tax_total"`
+	Total         int64               `json:"total"`
+	IssueDate     time.Time           `json:"issue_date"`
+	DueDate       *time.Time          `json:"due_date,omitempty"`
+	PaymentID     string              `json:"payment_id,omitempty"`
+	PaidAt        *time.Time          `json:"paid_at,omitempty"`
+	CreatedAt     time.Time           `json:"created_at"`
+	UpdatedAt     time.Time           `json:"updated_at"`
+}
@@ -0,0 +1,44 @@
+package model
+
+import "time"
+
+// DefaultMerchantID owns records created before merchants were introduced and
+// is assumed for credentials that do not name a merchant.
+const DefaultMerchantID = "default"
+
+// Merchant holds the per-merchant configuration applied when creating
+// payments, invoices and receipts.
+type Merchant struct {
+	ID                  string   `json:"id"`
+	Name                string   `json:"name"`
+	AllowedCurrencies   []string `json:"allowed_currencies"`
+	PaymentMethods      []string `json:"payment_methods"`
+	TransactionIDPrefix string   `json:"transaction_id_prefix"`
+	MinAmount           int64    `json:"min_amount"`
+	MaxAmount           int64    `json:"max_amount"`
+	// InvoiceIssuer is printed on the merchant's qualified invoices and
+	// receipts.
+	InvoiceIssuer InvoiceIssuer `json:"invoice_issuer"`
+	// Plan selects the merchant's rate limits. It is managed by the platform
+	// operator and cannot be changed through the merchant API.
+	Plan      string    `json:"plan"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (m *Merchant) AllowsCurrency(currency string) bool {
+	return contains(m.AllowedCurrencies, currency)
+}
+
+func (m *Merchant) AllowsPaymentMethod(method string) bool {
+	return contains(m.PaymentMethods, method)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
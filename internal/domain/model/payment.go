@@ -5,6 +5,7 @@ import "time"
 type PaymentStatus string
 
 const (
+	PaymentStatusScheduled  PaymentStatus = "scheduled"
 	PaymentStatusPending    PaymentStatus = "pending"
 	PaymentStatusProcessing PaymentStatus = "processing"
 	PaymentStatusCompleted  PaymentStatus = "completed"
@@ -14,31 +15,29 @@ const (
 
 type Payment struct {
 	ID            string          `json:"id"`
+	MerchantID    string          `json:"merchant_id"`
+	Livemode      bool            `json:"livemode"`
 	Amount        int64           `json:"amount"`
 	Currency      string          `json:"currency"`
 	Status        PaymentStatus   `json:"status"`
-	Description   string          `json:"description"`
-	CustomerID    string          `json:"customer_id"`
+	Description   string          `json:"description" log:"drop"`
+	CustomerID    string          `json:"customer_id" log:"hash"`
 	CreatedAt     time.Time       `json:"created_at"`
 	UpdatedAt     time.Time       `json:"updated_at"`
 	TransactionID string          `json:"transaction_id"`
 	Metadata      PaymentMetadata `json:"metadata"`
+	ScheduledAt   *time.Time      `json:"scheduled_at,omitempty"`
+	CreatedBy     string          `json:"created_by,omitempty"`
+	// IdempotencyKey identifies the charge to the processor, so a retried
+	// charge for the same key is not taken twice.
+	IdempotencyKey string `json:"idempotency_key,omitempty"`
 }
 
 type PaymentMetadata struct {
-	OrderID       string `json:"order_id"`
-	ProductID     string `json:"product_id"`
-	PaymentMethod string `json:"payment_method"`
-}
-
-type PaymentRepository interface {
-	Create(payment *Payment) error
-	FindByID(id string) (*Payment, error)
-	Update(payment *Payment) error
-	List(limit int, offset int) ([]*Payment, error)
-}
-
-type PaymentProcessor interface {
-	Process(payment *Payment) error
-	Cancel(payment *Payment) error
+	OrderID        string       `json:"order_id"`
+	ProductID      string       `json:"product_id"`
+	PaymentMethod  string       `json:"payment_method"`
+	SubscriptionID string       `json:"subscription_id,omitempty"`
+	InvoiceID      string       `json:"invoice_id,omitempty"`
+	Installment    *Installment `json:"installment,omitempty"`
 }
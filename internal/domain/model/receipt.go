@@ -0,0 +1,29 @@
+package model
+
+import "time"
+
+type Receipt struct {
+	ID            string        `json:"id"`
+	MerchantID    string        `json:"merchant_id"`
+	Livemode      bool          `json:"livemode"`
+	ReceiptNumber string        `json:"receipt_number"`
+	PaymentID     string        `json:"payment_id"`
+	TransactionID string        `json:"transaction_id"`
+	Amount        int64         `json:"amount"`
+	Currency      string        `json:"currency"`
+	RecipientName string        `json:"recipient_name" log:"mask"`
+	Proviso       string        `json:"proviso"`
+	Issuer        InvoiceIssuer `json:"issuer"`
+	PaidAt        time.Time     `json:"paid_at"`
+	IssuedAt      time.Time     `json:"issued_at"`
+	// ReissueCount is the number of times the receipt has been issued again
+	// after the original. Any copy other than the original is marked as a
+	// reissue on the rendered document.
+	ReissueCount  int        `json:"reissue_count"`
+	ReissuedAt    *time.Time `json:"reissued_at,omitempty"`
+	ReissueReason string     `json:"reissue_reason,omitempty"`
+}
+
+func (r *Receipt) Reissued() bool {
+	return r.ReissueCount > 0
+}
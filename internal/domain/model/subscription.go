@@ -0,0 +1,62 @@
+package model
+
+import "time"
+
+type PlanInterval string
+
+const (
+	PlanIntervalDay   PlanInterval = "day"
+	PlanIntervalWeek  PlanInterval = "week"
+	PlanIntervalMonth PlanInterval = "month"
+	PlanIntervalYear  PlanInterval = "year"
+)
+
+type Plan struct {
+	ID              string       `json:"id"`
+	MerchantID      string       `json:"merchant_id"`
+	Livemode        bool         `json:"livemode"`
+	Name            string       `json:"name"`
+	Amount          int64        `json:"amount"`
+	Currency        string       `json:"currency"`
+	Interval        PlanInterval `json:"interval"`
+	IntervalCount   int          `json:"interval_count"`
+	TrialPeriodDays int          `json:"trial_period_days"`
+	CreatedAt       time.Time    `json:"created_at"`
+	UpdatedAt       time.Time    `json:"updated_at"`
+}
+
+type SubscriptionStatus string
+
+// A subscription is incomplete from its creation until its first payment
+// succeeds; it is canceled if that payment fails.
+const (
+	SubscriptionStatusIncomplete SubscriptionStatus = "incomplete"
+	SubscriptionStatusActive     SubscriptionStatus = "active"
+	SubscriptionStatusPastDue    SubscriptionStatus = "past_due"
+	SubscriptionStatusCanceled   SubscriptionStatus = "canceled"
+)
+
+type Subscription struct {
+	ID                 string             `json:"id"`
+	MerchantID         string             `json:"merchant_id"`
+	Livemode           bool               `json:"livemode"`
+	CustomerID         string             `json:"customer_id" log:"hash"`
+	PlanID             string             `json:"plan_id"`
+	PaymentMethod      string             `json:"payment_method"`
+	Status             SubscriptionStatus `json:"status"`
+	BillingAnchor      time.Time          `json:"billing_anchor"`
+	CurrentPeriodStart time.Time          `json:"current_period_start"`
+	CurrentPeriodEnd   time.Time          `json:"current_period_end"`
+	TrialEnd           *time.Time         `json:"trial_end,omitempty"`
+	CancelAtPeriodEnd  bool               `json:"cancel_at_period_end"`
+	CanceledAt         *time.Time         `json:"canceled_at,omitempty"`
+	RetryCount         int                `json:"retry_count"`
+	NextRetryAt        *time.Time         `json:"next_retry_at,omitempty"`
+	LastPaymentID      string             `json:"last_payment_id,omitempty"`
+	CreatedAt          time.Time          `json:"created_at"`
+	UpdatedAt          time.Time          `json:"updated_at"`
+}
+
+func (s *Subscription) InTrial(now time.Time) bool {
+	return s.TrialEnd != nil && now.Before(*s.TrialEnd)
+}
@@ -0,0 +1,11 @@
+package model
+
+// TestDataDeletion reports how many test mode records were removed for a
+// merchant. Credentials are not test data and are never removed.
+type TestDataDeletion struct {
+	Payments      int64 `json:"payments"`
+	Receipts      int64 `json:"receipts"`
+	Subscriptions int64 `json:"subscriptions"`
+	Plans         int64 `json:"plans"`
+	Invoices      int64 `json:"invoices"`
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"time"
+
+	"GO-API/internal/domain/model"
+)
+
+// AddBillingPeriods returns the date that is n billing periods after anchor.
+// Monthly and yearly periods are always computed from the anchor rather than
+// from the previous period end, so an anchor on the 31st bills on the last day
+// of shorter months without drifting to an earlier day afterwards.
+func AddBillingPeriods(anchor time.Time, interval model.PlanInterval, count, n int) time.Time {
+	switch interval {
+	case model.PlanIntervalDay:
+		return anchor.AddDate(0, 0, count*n)
+	case model.PlanIntervalWeek:
+		return anchor.AddDate(0, 0, 7*count*n)
+	case model.PlanIntervalYear:
+		return addMonthsClamped(anchor, 12*count*n)
+	default:
+		return addMonthsClamped(anchor, count*n)
+	}
+}
+
+// NextBillingDate returns the first period boundary derived from anchor that is
+// strictly after t.
+func NextBillingDate(anchor, t time.Time, interval model.PlanInterval, count int) time.Time {
+	if anchor.After(t) {
+		return anchor
+	}
+
+	n := 1
+	next := AddBillingPeriods(anchor, interval, count, n)
+	for !next.After(t) {
+		n++
+		next = AddBillingPeriods(anchor, interval, count, n)
+	}
+	return next
+}
+
+func addMonthsClamped(t time.Time, months int) time.Time {
+	year, month, day := t.Date()
+	firstOfTarget := time.Date(year, month+time.Month(months), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	lastDay := firstOfTarget.AddDate(0, 1, -1).Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return firstOfTarget.AddDate(0, 0, day-1)
+}
+
+func ValidPlanInterval(interval model.PlanInterval) bool {
+	switch interval {
+	case model.PlanIntervalDay, model.PlanIntervalWeek, model.PlanIntervalMonth, model.PlanIntervalYear:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"math"
+	"regexp"
+	"sort"
+
+	"GO-API/internal/domain/model"
+)
+
+var registrationNumberPattern = regexp.MustCompile(`^T[0-9]{13}$`)
+
+func ValidateRegistrationNumber(number string) error {
+	if !registrationNumberPattern.MatchString(number) {
+		return model.NewValidationError("registration number must be T followed by 13 digits")
+	}
+	return nil
+}
+
+func ValidTaxRate(rate model.TaxRate) bool {
+	return rate == model.TaxRateStandard || rate == model.TaxRateReduced
+}
+
+func ValidTaxRounding(rounding model.TaxRounding) bool {
+	switch rounding {
+	case model.TaxRoundingFloor, model.TaxRoundingHalfUp, model.TaxRoundingCeiling:
+		return true
+	}
+	return false
+}
+
+type InvoiceTotals struct {
+	TaxSummaries []model.InvoiceTaxSummary
+	Subtotal     int64
+	TaxTotal     int64
+	Total        int64
+}
+
+// CalculateInvoiceTotals fills in each line item amount and computes the
+// consumption tax once per rate as required for qualified invoices. When
+// taxInclusive is true, unit prices already include tax and the tax portion is
+// extracted from the per-rate total. It returns a validation error if any
+// amount does not fit in an int64.
+func CalculateInvoiceTotals(items []model.InvoiceLineItem, taxInclusive bool, rounding model.TaxRounding) (InvoiceTotals, error) {
+	errOutOfRange := model.NewValidationError("invoice amounts are out of range")
+
+	byRate := make(map[model.TaxRate]int64)
+	for i := range items {
+		amount, ok := mulInt64(items[i].Quantity, items[i].UnitPrice)
+		if !ok {
+			return InvoiceTotals{}, errOutOfRange
+		}
+		items[i].Amount = amount
+		if byRate[items[i].TaxRate], ok = addInt64(byRate[items[i].TaxRate], amount); !ok {
+			return InvoiceTotals{}, errOutOfRange
+		}
+	}
+
+	rates := make([]model.TaxRate, 0, len(byRate))
+	for rate := range byRate {
+		rates = append(rates, rate)
+	}
+	sort.Slice(rates, func(i, j int) bool { return rates[i] > rates[j] })
+
+	var totals InvoiceTotals
+	for _, rate := range rates {
+		amount := byRate[rate]
+
+		scaled, ok := mulInt64(amount, int64(rate))
+		if !ok {
+			return InvoiceTotals{}, errOutOfRange
+		}
+
+		var tax, taxable int64
+		if taxInclusive {
+			tax = divide(scaled, 100+int64(rate), rounding)
+			taxable = amount - tax
+		} else {
+			tax = divide(scaled, 100, rounding)
+			taxable = amount
+		}
+
+		totals.TaxSummaries = append(totals.TaxSummaries, model.InvoiceTaxSummary{
+			TaxRate:       rate,
+			TaxableAmount: taxable,
+			TaxAmount:     tax,
+		})
+		if totals.Subtotal, ok = addInt64(totals.Subtotal, taxable); !ok {
+			return InvoiceTotals{}, errOutOfRange
+		}
+		if totals.TaxTotal, ok = addInt64(totals.TaxTotal, tax); !ok {
+			return InvoiceTotals{}, errOutOfRange
+		}
+	}
+
+	var ok bool
+	if totals.Total, ok = addInt64(totals.Subtotal, totals.TaxTotal); !ok {
+		return InvoiceTotals{}, errOutOfRange
+	}
+	return totals, nil
+}
+
+// mulInt64 and addInt64 report false instead of wrapping around. Amounts are
+// never negative, so only overflow past math.MaxInt64 is checked.
+func mulInt64(a, b int64) (int64, bool) {
+	if a != 0 && b > math.MaxInt64/a {
+		return 0, false
+	}
+	return a * b, true
+}
+
+func addInt64(a, b int64) (int64, bool) {
+	if b > math.MaxInt64-a {
+		return 0, false
+	}
+	return a + b, true
+}
+
+func divide(numerator, denominator int64, rounding model.TaxRounding) int64 {
+	switch rounding {
+	case model.TaxRoundingHalfUp:
+		return (numerator + denominator/2) / denominator
+	case model.TaxRoundingCeiling:
+		return (numerator + denominator - 1) / denominator
+	default:
+		return numerator / denominator
+	}
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"time"
+
+	"GO-API/internal/domain/model"
+)
+
+// allowedInstallmentCounts lists the installment counts accepted per payment
+// method. Methods not listed only support lump-sum payment.
+var allowedInstallmentCounts = map[string][]int{
+	"credit_card": {3, 5, 6, 10, 12, 15, 18, 20, 24},
+}
+
+var installmentMethods = map[string]bool{
+	"credit_card": true,
+}
+
+func ValidateInstallment(paymentMethod string, amount int64, installment *model.Installment) error {
+	if installment == nil || installment.Type == model.InstallmentTypeLumpSum {
+		if installment != nil && installment.Count != 0 {
+			return model.NewValidationError("installment count is only allowed for installments")
+		}
+		return nil
+	}
+
+	if !installmentMethods[paymentMethod] {
+		return model.NewValidationError("payment method does not support installments")
+	}
+
+	switch installment.Type {
+	case model.InstallmentTypeInstallments:
+		if !containsInt(allowedInstallmentCounts[paymentMethod], installment.Count) {
+			return model.NewValidationError("unsupported installment count")
+		}
+		if amount < int64(installment.Count) {
+			return model.NewValidationError("amount is too small for the installment count")
+		}
+	case model.InstallmentTypeBonus, model.InstallmentTypeRevolving:
+		if installment.Count != 0 {
+			return model.NewValidationError("installment count is only allowed for installments")
+		}
+	default:
+		return model.NewValidationError("unsupported installment type")
+	}
+
+	return nil
+}
+
+// BuildInstallmentSchedule splits amount into the expected charges. Any
+// remainder from an uneven split is added to the first charge, as Japanese
+// card issuers do. Revolving payments have no fixed schedule.
+func BuildInstallmentSchedule(amount int64, installment *model.Installment, purchasedAt time.Time) []model.InstallmentSchedule {
+	firstBilling := AddBillingPeriods(purchasedAt, model.PlanIntervalMonth, 1, 1)
+
+	switch installment.Type {
+	case model.InstallmentTypeInstallments:
+		count := int64(installment.Count)
+		each := amount / count
+		schedule := make([]model.InstallmentSchedule, installment.Count)
+		for i := range schedule {
+			schedule[i] = model.InstallmentSchedule{
+				Number:  i + 1,
+				DueDate: AddBillingPeriods(firstBilling, model.PlanIntervalMonth, 1, i),
+				Amount:  each,
+			}
+		}
+		schedule[0].Amount += amount - each*count
+		return schedule
+	case model.InstallmentTypeBonus:
+		return []model.InstallmentSchedule{{Number: 1, DueDate: nextBonusMonth(purchasedAt), Amount: amount}}
+	case model.InstallmentTypeRevolving:
+		return nil
+	default:
+		return []model.InstallmentSchedule{{Number: 1, DueDate: firstBilling, Amount: amount}}
+	}
+}
+
+// nextBonusMonth returns the next summer (August) or winter (January) bonus
+// billing month after t.
+func nextBonusMonth(t time.Time) time.Time {
+	summer := time.Date(t.Year(), time.August, 1, 0, 0, 0, 0, t.Location())
+	if summer.After(t) {
+		return summer
+	}
+	return time.Date(t.Year()+1, time.January, 1, 0, 0, 0, 0, t.Location())
+}
+
+func containsInt(values []int, v int) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
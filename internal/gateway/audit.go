@@ -0,0 +1,12 @@
+package gateway
+
+import (
+	"context"
+
+	"GO-API/internal/domain/model"
+)
+
+type AuditRepository interface {
+	Create(ctx context.Context, entry *model.AuditEntry) error
+	List(ctx context.Context, merchantID string, limit int, offset int) ([]*model.AuditEntry, error)
+}
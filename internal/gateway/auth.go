@@ -0,0 +1,42 @@
+package gateway
+
+import (
+	"context"
+	"time"
+
+	"GO-API/internal/domain/model"
+)
+
+type APIClientRepository interface {
+	Create(ctx context.Context, client *model.APIClient) error
+	FindByID(ctx context.Context, id string) (*model.APIClient, error)
+}
+
+type RefreshTokenRepository interface {
+	Create(ctx context.Context, token *model.RefreshToken) error
+	FindByHash(ctx context.Context, tokenHash string) (*model.RefreshToken, error)
+	// MarkUsed spends an active token and reports whether this call was the
+	// one that spent it.
+	MarkUsed(ctx context.Context, id string, usedAt time.Time) (bool, error)
+	RevokeFamily(ctx context.Context, familyID string, revokedAt time.Time) error
+}
+
+// TokenDenylist records revoked access tokens by jti until they expire.
+type TokenDenylist interface {
+	Revoke(ctx context.Context, jti string, expiresAt time.Time) error
+	IsRevoked(ctx context.Context, jti string) (bool, error)
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
+
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *model.APIKey) error
+	FindByID(ctx context.Context, merchantID string, id string) (*model.APIKey, error)
+	// FindByHash spans all merchants since it identifies the merchant of a
+	// presented key.
+	FindByHash(ctx context.Context, keyHash string) (*model.APIKey, error)
+	List(ctx context.Context, merchantID string, limit int, offset int) ([]*model.APIKey, error)
+	Update(ctx context.Context, key *model.APIKey) error
+	// TouchLastUsed records a use of the key, writing at most once per
+	// minInterval to keep authentication cheap.
+	TouchLastUsed(ctx context.Context, id string, usedAt time.Time, minInterval time.Duration) error
+}
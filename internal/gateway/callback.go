@@ -0,0 +1,23 @@
+package gateway
+
+import (
+	"context"
+	"time"
+
+	"GO-API/internal/domain/model"
+)
+
+// CallbackAdapter translates a provider's verified callback body into a
+// payment status notification.
+type CallbackAdapter interface {
+	ParseCallback(body []byte) (*model.PaymentCallback, error)
+}
+
+type CallbackNonceRepository interface {
+	// Use records nonce for provider until expiresAt and reports whether it
+	// had not been seen before.
+	Use(ctx context.Context, provider string, nonce string, expiresAt time.Time) (bool, error)
+	// Release forgets nonce so the callback can be delivered again.
+	Release(ctx context.Context, provider string, nonce string) error
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
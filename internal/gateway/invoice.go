@@ -0,0 +1,21 @@
+package gateway
+
+import (
+	"context"
+	"time"
+
+	"GO-API/internal/domain/model"
+)
+
+type InvoiceRepository interface {
+	Create(ctx context.Context, invoice *model.Invoice) error
+	FindByID(ctx context.Context, merchantID string, livemode bool, id string) (*model.Invoice, error)
+	Update(ctx context.Context, invoice *model.Invoice) error
+	// ReservePayment runs reserve with the invoice row locked and stores the
+	// PaymentID it sets.
+	ReservePayment(ctx context.Context, merchantID string, livemode bool, id string, reserve func(invoice *model.Invoice) error) (*model.Invoice, error)
+	List(ctx context.Context, merchantID string, livemode bool, limit int, offset int) ([]*model.Invoice, error)
+	// NextInvoiceNumber allocates the next number in merchantID's own
+	// invoice sequence.
+	NextInvoiceNumber(ctx context.Context, merchantID string, issueDate time.Time) (string, error)
+}
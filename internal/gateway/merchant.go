@@ -0,0 +1,18 @@
+package gateway
+
+import (
+	"context"
+
+	"GO-API/internal/domain/model"
+)
+
+type MerchantRepository interface {
+	FindByID(ctx context.Context, id string) (*model.Merchant, error)
+	Save(ctx context.Context, merchant *model.Merchant) error
+}
+
+type TestDataRepository interface {
+	// DeleteTestData removes every test mode payment, receipt and
+	// subscription of the merchant in a single transaction.
+	DeleteTestData(ctx context.Context, merchantID string) (*model.TestDataDeletion, error)
+}
@@ -1,17 +1,32 @@
 package gateway
 
 import (
+	"context"
+	"time"
+
 	"GO-API/internal/domain/model"
 )
 
 type PaymentRepository interface {
-	Create(payment *model.Payment) error
-	FindByID(id string) (*model.Payment, error)
-	Update(payment *model.Payment) error
-	List(limit int, offset int) ([]*model.Payment, error)
+	// Create returns false if a payment with the same merchant, livemode and
+	// idempotency key already exists, in which case nothing is inserted.
+	Create(ctx context.Context, payment *model.Payment) (bool, error)
+	FindByID(ctx context.Context, merchantID string, id string) (*model.Payment, error)
+	Update(ctx context.Context, payment *model.Payment) error
+	List(ctx context.Context, merchantID string, livemode bool, limit int, offset int) ([]*model.Payment, error)
+	UpdateIfStatus(ctx context.Context, payment *model.Payment, expected model.PaymentStatus) (bool, error)
+	// ClaimDueScheduled spans all merchants; it is only used by the
+	// scheduler, which acts on each payment's own merchant. Claimed payments
+	// are leased until claimedUntil and claimed again if still processing
+	// once it has passed.
+	ClaimDueScheduled(ctx context.Context, now time.Time, claimedUntil time.Time, limit int) ([]*model.Payment, error)
+	// FindByTransactionID spans all merchants; it is only used for processor
+	// callbacks, which identify payments by transaction ID alone.
+	FindByTransactionID(ctx context.Context, transactionID string) (*model.Payment, error)
+	FindByIdempotencyKey(ctx context.Context, merchantID string, livemode bool, key string) (*model.Payment, error)
 }
 
 type PaymentProcessor interface {
-	Process(payment *model.Payment) error
-	Cancel(payment *model.Payment) error
+	Process(ctx context.Context, payment *model.Payment) error
+	Cancel(ctx context.Context, payment *model.Payment) error
 }
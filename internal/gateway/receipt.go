@@ -0,0 +1,17 @@
+package gateway
+
+import (
+	"context"
+	"time"
+
+	"GO-API/internal/domain/model"
+)
+
+type ReceiptRepository interface {
+	// Create reports false, without error, when the payment already has a
+	// receipt.
+	Create(ctx context.Context, receipt *model.Receipt) (bool, error)
+	FindByPaymentID(ctx context.Context, merchantID string, livemode bool, paymentID string) (*model.Receipt, error)
+	Reissue(ctx context.Context, receipt *model.Receipt) error
+	NextReceiptNumber(ctx context.Context, issueDate time.Time) (string, error)
+}
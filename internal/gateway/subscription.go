@@ -0,0 +1,24 @@
+package gateway
+
+import (
+	"context"
+	"time"
+
+	"GO-API/internal/domain/model"
+)
+
+type PlanRepository interface {
+	Create(ctx context.Context, plan *model.Plan) error
+	FindByID(ctx context.Context, merchantID string, livemode bool, id string) (*model.Plan, error)
+	List(ctx context.Context, merchantID string, livemode bool, limit int, offset int) ([]*model.Plan, error)
+}
+
+type SubscriptionRepository interface {
+	Create(ctx context.Context, subscription *model.Subscription) error
+	FindByID(ctx context.Context, merchantID string, id string) (*model.Subscription, error)
+	Update(ctx context.Context, subscription *model.Subscription) error
+	List(ctx context.Context, merchantID string, livemode bool, limit int, offset int) ([]*model.Subscription, error)
+	// ClaimDue spans all merchants for the renewal scheduler. It leases the
+	// returned subscriptions until claimedUntil or their next Update.
+	ClaimDue(ctx context.Context, now time.Time, claimedUntil time.Time, limit int) ([]*model.Subscription, error)
+}
@@ -0,0 +1,205 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	"GO-API/internal/domain/model"
+	"GO-API/internal/pkg/logger"
+)
+
+type APIKeyRepository struct {
+	db *sql.DB
+}
+
+func NewAPIKeyRepository(db *sql.DB) *APIKeyRepository {
+	return &APIKeyRepository{
+		db: db,
+	}
+}
+
+const createAPIKeysTableSQL = `
+CREATE TABLE IF NOT EXISTS api_keys (
+	id TEXT PRIMARY KEY,
+	name TEXT NOT NULL,
+	livemode BOOLEAN NOT NULL,
+	last4 TEXT NOT NULL,
+	key_hash TEXT NOT NULL UNIQUE,
+	scopes TEXT[] NOT NULL,
+	allowed_ips TEXT[] NOT NULL,
+	created_at TIMESTAMP NOT NULL,
+	last_used_at TIMESTAMP,
+	expires_at TIMESTAMP,
+	revoked_at TIMESTAMP);
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS merchant_id TEXT NOT NULL DEFAULT 'default';`
+
+const apiKeyColumns = `id, name, livemode, last4, key_hash, scopes, allowed_ips,
+	created_at, last_used_at, expires_at, revoked_at, merchant_id`
+
+func (r *APIKeyRepository) InitTable() error {
+	_, err := r.db.Exec(createAPIKeysTableSQL)
+	return err
+}
+
+func (r *APIKeyRepository) Create(ctx context.Context, key *model.APIKey) error {
+	logger.InfoContext(ctx, "Creating API key", "api_key_id", key.ID, "livemode", key.Livemode)
+
+	query := `
+		INSERT INTO api_keys (` + apiKeyColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`
+
+	_, err := r.db.ExecContext(
+		ctx,
+		query,
+		key.ID,
+		key.Name,
+		key.Livemode,
+		key.Last4,
+		key.KeyHash,
+		pq.Array(key.Scopes),
+		pq.Array(key.AllowedIPs),
+		key.CreatedAt,
+		key.LastUsedAt,
+		key.ExpiresAt,
+		key.RevokedAt,
+		key.MerchantID,
+	)
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to execute insert query", "error", err)
+		return fmt.Errorf("error creating api key: %w", err)
+	}
+	return nil
+}
+
+func (r *APIKeyRepository) FindByID(ctx context.Context, merchantID string, id string) (*model.APIKey, error) {
+	query := `
+		SELECT ` + apiKeyColumns + `
+		FROM api_keys
+		WHERE id = $1 AND merchant_id = $2`
+
+	return r.find(ctx, query, id, merchantID)
+}
+
+func (r *APIKeyRepository) FindByHash(ctx context.Context, keyHash string) (*model.APIKey, error) {
+	query := `
+		SELECT ` + apiKeyColumns + `
+		FROM api_keys
+		WHERE key_hash = $1`
+
+	return r.find(ctx, query, keyHash)
+}
+
+func (r *APIKeyRepository) List(ctx context.Context, merchantID string, limit int, offset int) ([]*model.APIKey, error) {
+	query := `
+		SELECT ` + apiKeyColumns + `
+		FROM api_keys
+		WHERE merchant_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3`
+
+	rows, err := r.db.QueryContext(ctx, query, merchantID, limit, offset)
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to execute list query", "error", err)
+		return nil, fmt.Errorf("error listing api keys: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []*model.APIKey
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			logger.ErrorContext(ctx, "Failed to scan api key row", "error", err)
+			return nil, fmt.Errorf("error scanning api key row: %w", err)
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+func (r *APIKeyRepository) Update(ctx context.Context, key *model.APIKey) error {
+	query := `
+		UPDATE api_keys
+		SET name = $1,
+			scopes = $2,
+			allowed_ips = $3,
+			expires_at = $4,
+			revoked_at = $5
+		WHERE id = $6 AND merchant_id = $7`
+
+	result, err := r.db.ExecContext(
+		ctx,
+		query,
+		key.Name,
+		pq.Array(key.Scopes),
+		pq.Array(key.AllowedIPs),
+		key.ExpiresAt,
+		key.RevokedAt,
+		key.ID,
+		key.MerchantID,
+	)
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to execute update query", "error", err)
+		return fmt.Errorf("error updating api key: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %w", err)
+	}
+	if rows == 0 {
+		return model.NewNotFoundError("api key not found")
+	}
+	return nil
+}
+
+func (r *APIKeyRepository) TouchLastUsed(ctx context.Context, id string, usedAt time.Time, minInterval time.Duration) error {
+	query := `
+		UPDATE api_keys
+		SET last_used_at = $2
+		WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < $3)`
+
+	if _, err := r.db.ExecContext(ctx, query, id, usedAt, usedAt.Add(-minInterval)); err != nil {
+		logger.ErrorContext(ctx, "Failed to execute update query", "error", err)
+		return fmt.Errorf("error updating api key last use: %w", err)
+	}
+	return nil
+}
+
+func (r *APIKeyRepository) find(ctx context.Context, query string, args ...interface{}) (*model.APIKey, error) {
+	key, err := scanAPIKey(r.db.QueryRowContext(ctx, query, args...))
+	if err == sql.ErrNoRows {
+		return nil, model.NewNotFoundError("api key not found")
+	}
+	if err != nil {
+		logger.ErrorContext(ctx, "Database error", "error", err)
+		return nil, fmt.Errorf("error finding api key: %w", err)
+	}
+	return key, nil
+}
+
+func scanAPIKey(row rowScanner) (*model.APIKey, error) {
+	var key model.APIKey
+	err := row.Scan(
+		&key.ID,
+		&key.Name,
+		&key.Livemode,
+		&key.Last4,
+		&key.KeyHash,
+		pq.Array(&key.Scopes),
+		pq.Array(&key.AllowedIPs),
+		&key.CreatedAt,
+		&key.LastUsedAt,
+		&key.ExpiresAt,
+		&key.RevokedAt,
+		&key.MerchantID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
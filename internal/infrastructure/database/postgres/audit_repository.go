@@ -0,0 +1,77 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"GO-API/internal/domain/model"
+	"GO-API/internal/pkg/logger"
+)
+
+type AuditRepository struct {
+	db *sql.DB
+}
+
+func NewAuditRepository(db *sql.DB) *AuditRepository {
+	return &AuditRepository{
+		db: db,
+	}
+}
+
+const createAuditTableSQL = `
+CREATE TABLE IF NOT EXISTS audit_entries (
+	id TEXT PRIMARY KEY,
+	actor_id TEXT NOT NULL,
+	action TEXT NOT NULL,
+	resource_type TEXT NOT NULL,
+	resource_id TEXT NOT NULL,
+	created_at TIMESTAMP NOT NULL);
+CREATE INDEX IF NOT EXISTS audit_entries_resource_idx ON audit_entries (resource_type, resource_id);
+ALTER TABLE audit_entries ADD COLUMN IF NOT EXISTS merchant_id TEXT NOT NULL DEFAULT 'default';`
+
+func (r *AuditRepository) InitTable() error {
+	_, err := r.db.Exec(createAuditTableSQL)
+	return err
+}
+
+func (r *AuditRepository) Create(ctx context.Context, entry *model.AuditEntry) error {
+	query := `
+		INSERT INTO audit_entries (id, actor_id, action, resource_type, resource_id, created_at, merchant_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`
+
+	_, err := r.db.ExecContext(ctx, query, entry.ID, entry.ActorID, entry.Action, entry.ResourceType, entry.ResourceID, entry.CreatedAt, entry.MerchantID)
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to execute insert query", "error", err)
+		return fmt.Errorf("error creating audit entry: %w", err)
+	}
+	return nil
+}
+
+func (r *AuditRepository) List(ctx context.Context, merchantID string, limit int, offset int) ([]*model.AuditEntry, error) {
+	query := `
+		SELECT id, actor_id, action, resource_type, resource_id, created_at, merchant_id
+		FROM audit_entries
+		WHERE merchant_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3`
+
+	rows, err := r.db.QueryContext(ctx, query, merchantID, limit, offset)
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to execute list query", "error", err)
+		return nil, fmt.Errorf("error listing audit entries: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*model.AuditEntry
+	for rows.Next() {
+		var entry model.AuditEntry
+		if err := rows.Scan(&entry.ID, &entry.ActorID, &entry.Action, &entry.ResourceType, &entry.ResourceID, &entry.CreatedAt, &entry.MerchantID); err != nil {
+			logger.ErrorContext(ctx, "Failed to scan audit row", "error", err)
+			return nil, fmt.Errorf("error scanning audit row: %w", err)
+		}
+		entries = append(entries, &entry)
+	}
+
+	return entries, rows.Err()
+}
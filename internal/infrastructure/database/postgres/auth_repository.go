@@ -0,0 +1,232 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"GO-API/internal/domain/model"
+	"GO-API/internal/pkg/logger"
+)
+
+type APIClientRepository struct {
+	db *sql.DB
+}
+
+func NewAPIClientRepository(db *sql.DB) *APIClientRepository {
+	return &APIClientRepository{
+		db: db,
+	}
+}
+
+const createAPIClientsTableSQL = `
+CREATE TABLE IF NOT EXISTS api_clients (
+	id TEXT PRIMARY KEY,
+	name TEXT NOT NULL,
+	role TEXT NOT NULL,
+	secret_hash TEXT NOT NULL,
+	created_at TIMESTAMP NOT NULL,
+	disabled_at TIMESTAMP);
+ALTER TABLE api_clients ADD COLUMN IF NOT EXISTS merchant_id TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE api_clients ADD COLUMN IF NOT EXISTS livemode BOOLEAN NOT NULL DEFAULT TRUE;`
+
+func (r *APIClientRepository) InitTable() error {
+	_, err := r.db.Exec(createAPIClientsTableSQL)
+	return err
+}
+
+func (r *APIClientRepository) Create(ctx context.Context, client *model.APIClient) error {
+	logger.InfoContext(ctx, "Creating API client", "client_id", client.ID, "name", client.Name)
+
+	query := `
+		INSERT INTO api_clients (id, name, role, secret_hash, created_at, disabled_at, merchant_id, livemode)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+
+	_, err := r.db.ExecContext(ctx, query, client.ID, client.Name, client.Role, client.SecretHash, client.CreatedAt, client.DisabledAt, client.MerchantID, client.Livemode)
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to execute insert query", "error", err)
+		return fmt.Errorf("error creating api client: %w", err)
+	}
+	return nil
+}
+
+func (r *APIClientRepository) FindByID(ctx context.Context, id string) (*model.APIClient, error) {
+	query := `
+		SELECT id, name, role, secret_hash, created_at, disabled_at, merchant_id, livemode
+		FROM api_clients
+		WHERE id = $1`
+
+	var client model.APIClient
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&client.ID,
+		&client.Name,
+		&client.Role,
+		&client.SecretHash,
+		&client.CreatedAt,
+		&client.DisabledAt,
+		&client.MerchantID,
+		&client.Livemode,
+	)
+	if err == sql.ErrNoRows {
+		return nil, model.NewNotFoundError("api client not found")
+	}
+	if err != nil {
+		logger.ErrorContext(ctx, "Database error", "error", err)
+		return nil, fmt.Errorf("error finding api client: %w", err)
+	}
+	return &client, nil
+}
+
+type RefreshTokenRepository struct {
+	db *sql.DB
+}
+
+func NewRefreshTokenRepository(db *sql.DB) *RefreshTokenRepository {
+	return &RefreshTokenRepository{
+		db: db,
+	}
+}
+
+const createRefreshTokensTableSQL = `
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+	id TEXT PRIMARY KEY,
+	family_id TEXT NOT NULL,
+	client_id TEXT NOT NULL REFERENCES api_clients(id),
+	token_hash TEXT NOT NULL UNIQUE,
+	expires_at TIMESTAMP NOT NULL,
+	created_at TIMESTAMP NOT NULL,
+	used_at TIMESTAMP,
+	revoked_at TIMESTAMP);
+CREATE INDEX IF NOT EXISTS refresh_tokens_family_idx ON refresh_tokens (family_id);`
+
+func (r *RefreshTokenRepository) InitTable() error {
+	_, err := r.db.Exec(createRefreshTokensTableSQL)
+	return err
+}
+
+func (r *RefreshTokenRepository) Create(ctx context.Context, token *model.RefreshToken) error {
+	query := `
+		INSERT INTO refresh_tokens (id, family_id, client_id, token_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)`
+
+	_, err := r.db.ExecContext(ctx, query, token.ID, token.FamilyID, token.ClientID, token.TokenHash, token.ExpiresAt, token.CreatedAt)
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to execute insert query", "error", err)
+		return fmt.Errorf("error creating refresh token: %w", err)
+	}
+	return nil
+}
+
+func (r *RefreshTokenRepository) FindByHash(ctx context.Context, tokenHash string) (*model.RefreshToken, error) {
+	query := `
+		SELECT id, family_id, client_id, token_hash, expires_at, created_at, used_at, revoked_at
+		FROM refresh_tokens
+		WHERE token_hash = $1`
+
+	var token model.RefreshToken
+	err := r.db.QueryRowContext(ctx, query, tokenHash).Scan(
+		&token.ID,
+		&token.FamilyID,
+		&token.ClientID,
+		&token.TokenHash,
+		&token.ExpiresAt,
+		&token.CreatedAt,
+		&token.UsedAt,
+		&token.RevokedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, model.NewNotFoundError("refresh token not found")
+	}
+	if err != nil {
+		logger.ErrorContext(ctx, "Database error", "error", err)
+		return nil, fmt.Errorf("error finding refresh token: %w", err)
+	}
+	return &token, nil
+}
+
+func (r *RefreshTokenRepository) MarkUsed(ctx context.Context, id string, usedAt time.Time) (bool, error) {
+	query := `
+		UPDATE refresh_tokens
+		SET used_at = $2
+		WHERE id = $1 AND used_at IS NULL AND revoked_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, id, usedAt)
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to execute update query", "error", err)
+		return false, fmt.Errorf("error marking refresh token used: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("error getting rows affected: %w", err)
+	}
+	return rows > 0, nil
+}
+
+func (r *RefreshTokenRepository) RevokeFamily(ctx context.Context, familyID string, revokedAt time.Time) error {
+	query := `
+		UPDATE refresh_tokens
+		SET revoked_at = $2
+		WHERE family_id = $1 AND revoked_at IS NULL`
+
+	if _, err := r.db.ExecContext(ctx, query, familyID, revokedAt); err != nil {
+		logger.ErrorContext(ctx, "Failed to execute update query", "error", err)
+		return fmt.Errorf("error revoking refresh tokens: %w", err)
+	}
+	return nil
+}
+
+type TokenDenylist struct {
+	db *sql.DB
+}
+
+func NewTokenDenylist(db *sql.DB) *TokenDenylist {
+	return &TokenDenylist{
+		db: db,
+	}
+}
+
+const createRevokedTokensTableSQL = `
+CREATE TABLE IF NOT EXISTS revoked_tokens (
+	jti TEXT PRIMARY KEY,
+	expires_at TIMESTAMP NOT NULL);`
+
+func (r *TokenDenylist) InitTable() error {
+	_, err := r.db.Exec(createRevokedTokensTableSQL)
+	return err
+}
+
+func (r *TokenDenylist) Revoke(ctx context.Context, jti string, expiresAt time.Time) error {
+	query := `
+		INSERT INTO revoked_tokens (jti, expires_at)
+		VALUES ($1, $2)
+		ON CONFLICT (jti) DO NOTHING`
+
+	if _, err := r.db.ExecContext(ctx, query, jti, expiresAt); err != nil {
+		logger.ErrorContext(ctx, "Failed to execute insert query", "error", err)
+		return fmt.Errorf("error revoking token: %w", err)
+	}
+	return nil
+}
+
+func (r *TokenDenylist) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	var revoked bool
+	err := r.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM revoked_tokens WHERE jti = $1)`, jti).Scan(&revoked)
+	if err != nil {
+		logger.ErrorContext(ctx, "Database error", "error", err)
+		return false, fmt.Errorf("error checking revoked token: %w", err)
+	}
+	return revoked, nil
+}
+
+// DeleteExpired drops entries for tokens that have expired anyway and would be
+// rejected by the exp check.
+func (r *TokenDenylist) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM revoked_tokens WHERE expires_at < $1`, now)
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to execute delete query", "error", err)
+		return 0, fmt.Errorf("error deleting expired revoked tokens: %w", err)
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,75 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"GO-API/internal/pkg/logger"
+)
+
+type CallbackNonceRepository struct {
+	db *sql.DB
+}
+
+func NewCallbackNonceRepository(db *sql.DB) *CallbackNonceRepository {
+	return &CallbackNonceRepository{
+		db: db,
+	}
+}
+
+const createCallbackNoncesTableSQL = `
+CREATE TABLE IF NOT EXISTS callback_nonces (
+	provider TEXT NOT NULL,
+	nonce TEXT NOT NULL,
+	expires_at TIMESTAMP NOT NULL,
+	PRIMARY KEY (provider, nonce));`
+
+func (r *CallbackNonceRepository) InitTable() error {
+	_, err := r.db.Exec(createCallbackNoncesTableSQL)
+	return err
+}
+
+// Use inserts the nonce; the primary key makes concurrent deliveries of the
+// same callback race safely, with exactly one of them succeeding.
+func (r *CallbackNonceRepository) Use(ctx context.Context, provider string, nonce string, expiresAt time.Time) (bool, error) {
+	query := `
+		INSERT INTO callback_nonces (provider, nonce, expires_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (provider, nonce) DO NOTHING`
+
+	result, err := r.db.ExecContext(ctx, query, provider, nonce, expiresAt)
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to execute insert query", "error", err)
+		return false, fmt.Errorf("error recording callback nonce: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("error getting rows affected: %w", err)
+	}
+	return rows > 0, nil
+}
+
+// Release deletes the nonce so a redelivery of a callback that failed to be
+// applied is accepted.
+func (r *CallbackNonceRepository) Release(ctx context.Context, provider string, nonce string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM callback_nonces WHERE provider = $1 AND nonce = $2`, provider, nonce)
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to execute delete query", "error", err)
+		return fmt.Errorf("error releasing callback nonce: %w", err)
+	}
+	return nil
+}
+
+// DeleteExpired drops nonces whose timestamps are outside the accepted window
+// and would be rejected anyway.
+func (r *CallbackNonceRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM callback_nonces WHERE expires_at < $1`, now)
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to execute delete query", "error", err)
+		return 0, fmt.Errorf("error deleting expired callback nonces: %w", err)
+	}
+	return result.RowsAffected()
+}
@@ -1,40 +1,156 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"sort"
+	"strings"
+	"time"
 
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
+
+	"GO-API/internal/pkg/logger"
+)
+
+const (
+	// initialConnectBackoff and maxConnectBackoff bound the wait between
+	// connection attempts at startup.
+	initialConnectBackoff = 500 * time.Millisecond
+	maxConnectBackoff     = 10 * time.Second
+	// pingTimeout bounds a single connection attempt.
+	pingTimeout = 5 * time.Second
 )
 
 type Config struct {
+	// URL is a postgres:// connection URL. When set it replaces Host, Port,
+	// User, Password, DBName and SSLMode.
+	URL      string
 	Host     string
 	Port     int
 	User     string
 	Password string
 	DBName   string
-	SSLMode  string
+	// SSLMode is one of the modes lib/pq supports: disable, require,
+	// verify-ca or verify-full.
+	SSLMode     string
+	SSLRootCert string
+	SSLCert     string
+	SSLKey      string
+
+	ApplicationName string
+	// StatementTimeout aborts queries running longer; zero disables it.
+	StatementTimeout time.Duration
+
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+
+	// ConnectTimeout is how long NewConnection keeps retrying while the
+	// database is unavailable; zero means a single attempt.
+	ConnectTimeout time.Duration
+}
+
+// DSN returns the lib/pq connection string for config.
+func (config *Config) DSN() (string, error) {
+	params := make(map[string]string)
+	dsn := ""
+	if config.URL != "" {
+		parsed, err := pq.ParseURL(config.URL)
+		if err != nil {
+			return "", fmt.Errorf("invalid database URL: %w", err)
+		}
+		dsn = parsed
+	} else {
+		params["host"] = config.Host
+		params["port"] = fmt.Sprint(config.Port)
+		params["user"] = config.User
+		params["password"] = config.Password
+		params["dbname"] = config.DBName
+		params["sslmode"] = config.SSLMode
+	}
+
+	params["client_encoding"] = "UTF8"
+	setIfNotEmpty(params, "sslrootcert", config.SSLRootCert)
+	setIfNotEmpty(params, "sslcert", config.SSLCert)
+	setIfNotEmpty(params, "sslkey", config.SSLKey)
+	setIfNotEmpty(params, "application_name", config.ApplicationName)
+	if config.StatementTimeout > 0 {
+		// Unknown keys are sent to the server as run-time parameters.
+		params["statement_timeout"] = fmt.Sprint(config.StatementTimeout.Milliseconds())
+	}
+
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	// Later keys take precedence, so these extend or override the URL.
+	parts := make([]string, 0, len(keys)+1)
+	if dsn != "" {
+		parts = append(parts, dsn)
+	}
+	for _, key := range keys {
+		parts = append(parts, key+"="+quoteDSNValue(params[key]))
+	}
+	return strings.Join(parts, " "), nil
 }
 
-func NewConnection(config *Config) (*sql.DB, error) {
-	dsn := fmt.Sprintf(
-		"host=%s port=%d user=%s password=%s dbname=%s sslmode=%s client_encoding=UTF8",
-		config.Host,
-		config.Port,
-		config.User,
-		config.Password,
-		config.DBName,
-		config.SSLMode,
-	)
+func setIfNotEmpty(params map[string]string, key, value string) {
+	if value != "" {
+		params[key] = value
+	}
+}
+
+// quoteDSNValue quotes value as a libpq keyword/value connection string
+// value.
+func quoteDSNValue(value string) string {
+	value = strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value)
+	return "'" + value + "'"
+}
+
+// NewConnection opens a connection pool and waits, retrying with exponential
+// backoff for up to config.ConnectTimeout, until the database answers. This
+// lets the server start alongside a database that is still booting.
+func NewConnection(ctx context.Context, config *Config) (*sql.DB, error) {
+	dsn, err := config.DSN()
+	if err != nil {
+		return nil, err
+	}
 
 	db, err := sql.Open("postgres", dsn)
 	if err != nil {
 		return nil, fmt.Errorf("error opening database: %w", err)
 	}
+	db.SetMaxOpenConns(config.MaxOpenConns)
+	db.SetMaxIdleConns(config.MaxIdleConns)
+	db.SetConnMaxLifetime(config.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(config.ConnMaxIdleTime)
 
-	if err := db.Ping(); err != nil {
-		return nil, fmt.Errorf("error connectiong to the database: %w", err)
-	}
+	deadline := time.Now().Add(config.ConnectTimeout)
+	backoff := initialConnectBackoff
+	for attempt := 1; ; attempt++ {
+		pingCtx, cancel := context.WithTimeout(ctx, pingTimeout)
+		err = db.PingContext(pingCtx)
+		cancel()
+		if err == nil {
+			return db, nil
+		}
+
+		if time.Now().Add(backoff).After(deadline) {
+			db.Close()
+			return nil, fmt.Errorf("error connecting to the database after %d attempts: %w", attempt, err)
+		}
+		logger.WarnContext(ctx, "Database not available, retrying", "attempt", attempt, "retry_in", backoff, "error", err)
 
-	return db, nil
+		select {
+		case <-ctx.Done():
+			db.Close()
+			return nil, fmt.Errorf("error connecting to the database: %w", ctx.Err())
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxConnectBackoff)
+	}
 }
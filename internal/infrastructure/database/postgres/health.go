@@ -0,0 +1,34 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// SchemaChecker reports the schema as not ready until every table created by
+// the repositories' InitTable methods exists.
+type SchemaChecker struct {
+	db     *sql.DB
+	tables []string
+}
+
+func NewSchemaChecker(db *sql.DB, tables ...string) *SchemaChecker {
+	return &SchemaChecker{
+		db:     db,
+		tables: tables,
+	}
+}
+
+func (c *SchemaChecker) Check(ctx context.Context) error {
+	for _, table := range c.tables {
+		var exists bool
+		if err := c.db.QueryRowContext(ctx, `SELECT to_regclass($1) IS NOT NULL`, table).Scan(&exists); err != nil {
+			return fmt.Errorf("error checking table %s: %w", table, err)
+		}
+		if !exists {
+			return fmt.Errorf("table %s does not exist", table)
+		}
+	}
+	return nil
+}
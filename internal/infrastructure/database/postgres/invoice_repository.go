@@ -0,0 +1,336 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"GO-API/internal/domain/model"
+	"GO-API/internal/pkg/logger"
+)
+
+type InvoiceRepository struct {
+	db *sql.DB
+}
+
+func NewInvoiceRepository(db *sql.DB) *InvoiceRepository {
+	return &InvoiceRepository{
+		db: db,
+	}
+}
+
+// Invoice numbers are allocated per merchant from invoice_number_counters and
+// are unique per merchant. When the counters are introduced, merchants with
+// invoices start after the last value of the old global invoice_number_seq so
+// that their new numbers cannot repeat existing ones.
+const createInvoicesTableSQL = `
+CREATE SEQUENCE IF NOT EXISTS invoice_number_seq;
+CREATE TABLE IF NOT EXISTS invoices (
+	id TEXT PRIMARY KEY,
+	invoice_number TEXT NOT NULL,
+	issuer JSONB NOT NULL,
+	customer_id TEXT NOT NULL,
+	customer_name TEXT NOT NULL,
+	currency TEXT NOT NULL,
+	status TEXT NOT NULL,
+	tax_inclusive BOOLEAN NOT NULL,
+	tax_rounding TEXT NOT NULL,
+	line_items JSONB NOT NULL,
+	tax_summaries JSONB NOT NULL,
+	subtotal BIGINT NOT NULL,
+	tax_total BIGINT NOT NULL,
+	total BIGINT NOT NULL,
+	issue_date TIMESTAMP NOT NULL,
+	due_date TIMESTAMP,
+	payment_id TEXT,
+	paid_at TIMESTAMP,
+	created_at TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP NOT NULL);
+ALTER TABLE invoices ADD COLUMN IF NOT EXISTS merchant_id TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE invoices ADD COLUMN IF NOT EXISTS livemode BOOLEAN NOT NULL DEFAULT TRUE;
+ALTER TABLE invoices DROP CONSTRAINT IF EXISTS invoices_invoice_number_key;
+CREATE UNIQUE INDEX IF NOT EXISTS invoices_merchant_number_idx ON invoices (merchant_id, invoice_number);
+DO $$
+BEGIN
+	IF NOT EXISTS (
+		SELECT 1 FROM information_schema.tables
+		WHERE table_schema = current_schema() AND table_name = 'invoice_number_counters'
+	) THEN
+		CREATE TABLE invoice_number_counters (
+			merchant_id TEXT PRIMARY KEY,
+			last_number BIGINT NOT NULL);
+		INSERT INTO invoice_number_counters (merchant_id, last_number)
+			SELECT DISTINCT merchant_id, (SELECT last_value FROM invoice_number_seq) FROM invoices;
+	END IF;
+END $$;`
+
+const invoiceColumns = `id, invoice_number, issuer, customer_id, customer_name, currency,
+	status, tax_inclusive, tax_rounding, line_items, tax_summaries, subtotal,
+	tax_total, total, issue_date, due_date, payment_id, paid_at, created_at, updated_at, merchant_id, livemode`
+
+func (r *InvoiceRepository) InitTable() error {
+	_, err := r.db.Exec(createInvoicesTableSQL)
+	return err
+}
+
+func (r *InvoiceRepository) NextInvoiceNumber(ctx context.Context, merchantID string, issueDate time.Time) (string, error) {
+	query := `
+		INSERT INTO invoice_number_counters (merchant_id, last_number)
+		VALUES ($1, 1)
+		ON CONFLICT (merchant_id) DO UPDATE
+		SET last_number = invoice_number_counters.last_number + 1
+		RETURNING last_number`
+
+	var seq int64
+	if err := r.db.QueryRowContext(ctx, query, merchantID).Scan(&seq); err != nil {
+		logger.ErrorContext(ctx, "Failed to allocate invoice number", "merchant_id", merchantID, "error", err)
+		return "", fmt.Errorf("error allocating invoice number: %w", err)
+	}
+	return fmt.Sprintf("INV-%s-%06d", issueDate.Format("2006"), seq), nil
+}
+
+func (r *InvoiceRepository) Create(ctx context.Context, invoice *model.Invoice) error {
+	logger.InfoContext(ctx, "Creating invoice", "invoice_id", invoice.ID, "number", invoice.InvoiceNumber, "total", invoice.Total)
+
+	issuerJSON, lineItemsJSON, taxSummariesJSON, err := marshalInvoiceJSON(invoice)
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to marshal invoice", "error", err)
+		return err
+	}
+
+	query := `
+		INSERT INTO invoices (` + invoiceColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22)`
+
+	_, err = r.db.ExecContext(
+		ctx,
+		query,
+		invoice.ID,
+		invoice.InvoiceNumber,
+		issuerJSON,
+		invoice.CustomerID,
+		invoice.CustomerName,
+		invoice.Currency,
+		invoice.Status,
+		invoice.TaxInclusive,
+		invoice.TaxRounding,
+		lineItemsJSON,
+		taxSummariesJSON,
+		invoice.Subtotal,
+		invoice.TaxTotal,
+		invoice.Total,
+		invoice.IssueDate,
+		invoice.DueDate,
+		nullString(invoice.PaymentID),
+		invoice.PaidAt,
+		invoice.CreatedAt,
+		invoice.UpdatedAt,
+		invoice.MerchantID,
+		invoice.Livemode,
+	)
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to execute insert query", "error", err)
+		return fmt.Errorf("error creating invoice: %w", err)
+	}
+
+	return nil
+}
+
+func (r *InvoiceRepository) FindByID(ctx context.Context, merchantID string, livemode bool, id string) (*model.Invoice, error) {
+	logger.InfoContext(ctx, "Executing FindByID query", "invoice_id", id)
+
+	query := `SELECT ` + invoiceColumns + ` FROM invoices WHERE id = $1 AND merchant_id = $2 AND livemode = $3`
+
+	invoice, err := scanInvoice(r.db.QueryRowContext(ctx, query, id, merchantID, livemode))
+	if err == sql.ErrNoRows {
+		logger.ErrorContext(ctx, "Invoice not found", "invoice_id", id)
+		return nil, model.NewNotFoundError("invoice not found")
+	}
+	if err != nil {
+		logger.ErrorContext(ctx, "Database error", "error", err)
+		return nil, fmt.Errorf("error finding invoice: %w", err)
+	}
+
+	return invoice, nil
+}
+
+func (r *InvoiceRepository) List(ctx context.Context, merchantID string, livemode bool, limit int, offset int) ([]*model.Invoice, error) {
+	logger.InfoContext(ctx, "Executing invoice List query", "livemode", livemode, "limit", limit, "offset", offset)
+
+	query := `
+		SELECT ` + invoiceColumns + `
+		FROM invoices
+		WHERE merchant_id = $1 AND livemode = $2
+		ORDER BY created_at DESC
+		LIMIT $3 OFFSET $4`
+
+	rows, err := r.db.QueryContext(ctx, query, merchantID, livemode, limit, offset)
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to execute list query", "error", err)
+		return nil, fmt.Errorf("error listing invoices: %w", err)
+	}
+	defer rows.Close()
+
+	var invoices []*model.Invoice
+	for rows.Next() {
+		invoice, err := scanInvoice(rows)
+		if err != nil {
+			logger.ErrorContext(ctx, "Failed to scan invoice row", "error", err)
+			return nil, fmt.Errorf("error scanning invoice row: %w", err)
+		}
+		invoices = append(invoices, invoice)
+	}
+
+	return invoices, rows.Err()
+}
+
+func (r *InvoiceRepository) Update(ctx context.Context, invoice *model.Invoice) error {
+	logger.InfoContext(ctx, "Updating invoice", "invoice_id", invoice.ID)
+
+	query := `
+		UPDATE invoices
+		SET status = $1,
+			payment_id = $2,
+			paid_at = $3,
+			updated_at = $4
+		WHERE id = $5 AND merchant_id = $6`
+
+	invoice.UpdatedAt = time.Now()
+	result, err := r.db.ExecContext(
+		ctx,
+		query,
+		invoice.Status,
+		nullString(invoice.PaymentID),
+		invoice.PaidAt,
+		invoice.UpdatedAt,
+		invoice.ID,
+		invoice.MerchantID,
+	)
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to execute update query", "error", err)
+		return fmt.Errorf("error updating invoice: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to get affected rows", "error", err)
+		return fmt.Errorf("error getting rows affected: %w", err)
+	}
+	if rows == 0 {
+		logger.ErrorContext(ctx, "Invoice not found for update", "invoice_id", invoice.ID)
+		return model.NewNotFoundError("invoice not found")
+	}
+
+	return nil
+}
+
+// ReservePayment locks the invoice row, lets reserve decide whether a new
+// payment may be started and set the invoice's PaymentID, and stores that ID.
+// The lock serializes concurrent attempts to pay the same invoice. An error
+// returned by reserve is returned unchanged and nothing is stored.
+func (r *InvoiceRepository) ReservePayment(ctx context.Context, merchantID string, livemode bool, id string, reserve func(invoice *model.Invoice) error) (*model.Invoice, error) {
+	logger.InfoContext(ctx, "Reserving invoice payment", "invoice_id", id)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `SELECT ` + invoiceColumns + ` FROM invoices WHERE id = $1 AND merchant_id = $2 AND livemode = $3 FOR UPDATE`
+
+	invoice, err := scanInvoice(tx.QueryRowContext(ctx, query, id, merchantID, livemode))
+	if err == sql.ErrNoRows {
+		logger.ErrorContext(ctx, "Invoice not found", "invoice_id", id)
+		return nil, model.NewNotFoundError("invoice not found")
+	}
+	if err != nil {
+		logger.ErrorContext(ctx, "Database error", "error", err)
+		return nil, fmt.Errorf("error finding invoice: %w", err)
+	}
+
+	if err := reserve(invoice); err != nil {
+		return nil, err
+	}
+
+	invoice.UpdatedAt = time.Now()
+	_, err = tx.ExecContext(ctx, `
+		UPDATE invoices
+		SET payment_id = $1, updated_at = $2
+		WHERE id = $3 AND merchant_id = $4`,
+		nullString(invoice.PaymentID), invoice.UpdatedAt, invoice.ID, invoice.MerchantID)
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to execute update query", "error", err)
+		return nil, fmt.Errorf("error reserving invoice payment: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing invoice payment: %w", err)
+	}
+	return invoice, nil
+}
+
+func marshalInvoiceJSON(invoice *model.Invoice) ([]byte, []byte, []byte, error) {
+	issuerJSON, err := json.Marshal(invoice.Issuer)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	lineItemsJSON, err := json.Marshal(invoice.LineItems)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	taxSummariesJSON, err := json.Marshal(invoice.TaxSummaries)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return issuerJSON, lineItemsJSON, taxSummariesJSON, nil
+}
+
+func scanInvoice(row rowScanner) (*model.Invoice, error) {
+	var invoice model.Invoice
+	var issuerJSON, lineItemsJSON, taxSummariesJSON []byte
+	var paymentID sql.NullString
+
+	err := row.Scan(
+		&invoice.ID,
+		&invoice.InvoiceNumber,
+		&issuerJSON,
+		&invoice.CustomerID,
+		&invoice.CustomerName,
+		&invoice.Currency,
+		&invoice.Status,
+		&invoice.TaxInclusive,
+		&invoice.TaxRounding,
+		&lineItemsJSON,
+		&taxSummariesJSON,
+		&invoice.Subtotal,
+		&invoice.TaxTotal,
+		&invoice.Total,
+		&invoice.IssueDate,
+		&invoice.DueDate,
+		&paymentID,
+		&invoice.PaidAt,
+		&invoice.CreatedAt,
+		&invoice.UpdatedAt,
+		&invoice.MerchantID,
+		&invoice.Livemode,
+	)
+	if err != nil {
+		return nil, err
+	}
+	invoice.PaymentID = paymentID.String
+
+	if err := json.Unmarshal(issuerJSON, &invoice.Issuer); err != nil {
+		return nil, fmt.Errorf("error unmarshaling issuer: %w", err)
+	}
+	if err := json.Unmarshal(lineItemsJSON, &invoice.LineItems); err != nil {
+		return nil, fmt.Errorf("error unmarshaling line items: %w", err)
+	}
+	if err := json.Unmarshal(taxSummariesJSON, &invoice.TaxSummaries); err != nil {
+		return nil, fmt.Errorf("error unmarshaling tax summaries: %w", err)
+	}
+
+	return &invoice, nil
+}
@@ -0,0 +1,122 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+
+	"GO-API/internal/domain/model"
+	"GO-API/internal/pkg/logger"
+)
+
+type MerchantRepository struct {
+	db *sql.DB
+}
+
+func NewMerchantRepository(db *sql.DB) *MerchantRepository {
+	return &MerchantRepository{
+		db: db,
+	}
+}
+
+const createMerchantsTableSQL = `
+CREATE TABLE IF NOT EXISTS merchants (
+	id TEXT PRIMARY KEY,
+	name TEXT NOT NULL,
+	allowed_currencies TEXT[] NOT NULL,
+	payment_methods TEXT[] NOT NULL,
+	transaction_id_prefix TEXT NOT NULL,
+	min_amount BIGINT NOT NULL,
+	max_amount BIGINT NOT NULL,
+	created_at TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP NOT NULL);
+ALTER TABLE merchants ADD COLUMN IF NOT EXISTS plan TEXT NOT NULL DEFAULT '';
+ALTER TABLE merchants ADD COLUMN IF NOT EXISTS issuer_name TEXT NOT NULL DEFAULT '';
+ALTER TABLE merchants ADD COLUMN IF NOT EXISTS issuer_registration_number TEXT NOT NULL DEFAULT '';
+ALTER TABLE merchants ADD COLUMN IF NOT EXISTS issuer_address TEXT NOT NULL DEFAULT '';`
+
+func (r *MerchantRepository) InitTable() error {
+	_, err := r.db.Exec(createMerchantsTableSQL)
+	return err
+}
+
+func (r *MerchantRepository) FindByID(ctx context.Context, id string) (*model.Merchant, error) {
+	query := `
+		SELECT id, name, allowed_currencies, payment_methods, transaction_id_prefix,
+			min_amount, max_amount, created_at, updated_at, plan,
+			issuer_name, issuer_registration_number, issuer_address
+		FROM merchants
+		WHERE id = $1`
+
+	var merchant model.Merchant
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&merchant.ID,
+		&merchant.Name,
+		pq.Array(&merchant.AllowedCurrencies),
+		pq.Array(&merchant.PaymentMethods),
+		&merchant.TransactionIDPrefix,
+		&merchant.MinAmount,
+		&merchant.MaxAmount,
+		&merchant.CreatedAt,
+		&merchant.UpdatedAt,
+		&merchant.Plan,
+		&merchant.InvoiceIssuer.Name,
+		&merchant.InvoiceIssuer.RegistrationNumber,
+		&merchant.InvoiceIssuer.Address,
+	)
+	if err == sql.ErrNoRows {
+		return nil, model.NewNotFoundError("merchant not found")
+	}
+	if err != nil {
+		logger.ErrorContext(ctx, "Database error", "error", err)
+		return nil, fmt.Errorf("error finding merchant: %w", err)
+	}
+	return &merchant, nil
+}
+
+// Save inserts the merchant or replaces the configuration of an existing one.
+func (r *MerchantRepository) Save(ctx context.Context, merchant *model.Merchant) error {
+	logger.InfoContext(ctx, "Saving merchant", "merchant_id", merchant.ID)
+
+	query := `
+		INSERT INTO merchants (
+			id, name, allowed_currencies, payment_methods, transaction_id_prefix,
+			min_amount, max_amount, created_at, updated_at,
+			issuer_name, issuer_registration_number, issuer_address
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		ON CONFLICT (id) DO UPDATE
+		SET name = EXCLUDED.name,
+			allowed_currencies = EXCLUDED.allowed_currencies,
+			payment_methods = EXCLUDED.payment_methods,
+			transaction_id_prefix = EXCLUDED.transaction_id_prefix,
+			min_amount = EXCLUDED.min_amount,
+			max_amount = EXCLUDED.max_amount,
+			updated_at = EXCLUDED.updated_at,
+			issuer_name = EXCLUDED.issuer_name,
+			issuer_registration_number = EXCLUDED.issuer_registration_number,
+			issuer_address = EXCLUDED.issuer_address`
+
+	_, err := r.db.ExecContext(
+		ctx,
+		query,
+		merchant.ID,
+		merchant.Name,
+		pq.Array(merchant.AllowedCurrencies),
+		pq.Array(merchant.PaymentMethods),
+		merchant.TransactionIDPrefix,
+		merchant.MinAmount,
+		merchant.MaxAmount,
+		merchant.CreatedAt,
+		merchant.UpdatedAt,
+		merchant.InvoiceIssuer.Name,
+		merchant.InvoiceIssuer.RegistrationNumber,
+		merchant.InvoiceIssuer.Address,
+	)
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to execute upsert query", "error", err)
+		return fmt.Errorf("error saving merchant: %w", err)
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
@@ -8,6 +9,7 @@ import (
 
 	"GO-API/internal/domain/model"
 	"GO-API/internal/pkg/logger"
+	"GO-API/internal/pkg/tracing"
 )
 
 type PaymentRepository struct {
@@ -31,29 +33,48 @@ CREATE TABLE IF NOT EXISTS payments (
 	created_at TIMESTAMP NOT NULL,
 	updated_at TIMESTAMP NOT NULL,
 	transaction_id TEXT NOT NULL UNIQUE,
-	metadata JSONB NOT NULL);`
+	metadata JSONB NOT NULL);
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS scheduled_at TIMESTAMP;
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS created_by TEXT;
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS merchant_id TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS livemode BOOLEAN NOT NULL DEFAULT TRUE;
+DROP INDEX IF EXISTS payments_merchant_created_idx;
+CREATE INDEX IF NOT EXISTS payments_merchant_mode_created_idx ON payments (merchant_id, livemode, created_at DESC);
+CREATE INDEX IF NOT EXISTS payments_scheduled_idx ON payments (scheduled_at) WHERE status = 'scheduled';
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS idempotency_key TEXT;
+DROP INDEX IF EXISTS payments_idempotency_key_idx;
+CREATE UNIQUE INDEX IF NOT EXISTS payments_mode_idempotency_key_idx ON payments (merchant_id, livemode, idempotency_key) WHERE idempotency_key IS NOT NULL;
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS claimed_until TIMESTAMP;
+CREATE INDEX IF NOT EXISTS payments_claimed_idx ON payments (claimed_until) WHERE status = 'processing' AND claimed_until IS NOT NULL;`
+
+const paymentColumns = `id, amount, currency, status, description, customer_id,
+	created_at, updated_at, transaction_id, metadata, scheduled_at, created_by, merchant_id, livemode, idempotency_key`
 
 func (r *PaymentRepository) InitTable() error {
 	_, err := r.db.Exec(createTableSQL)
 	return err
 }
 
-func (r *PaymentRepository) Create(payment *model.Payment) error {
-	logger.Info("Creating payment: ID=%s, Amount=%d, Currency=%s",
-		payment.ID, payment.Amount, payment.Currency)
+// Create inserts payment. It returns false, without error, if the merchant
+// already has a payment in the same mode with the payment's idempotency key.
+func (r *PaymentRepository) Create(ctx context.Context, payment *model.Payment) (_ bool, err error) {
+	ctx, span := startQuerySpan(ctx, "PaymentRepository.Create", "INSERT", "payments")
+	defer tracing.End(span, &err)
+
+	logger.InfoContext(ctx, "Creating payment", "payment_id", payment.ID, "amount", payment.Amount, "currency", payment.Currency)
 
 	query := `
-		INSERT INTO payments (
-			id, amount, currency, status, description, customer_id,
-			created_at, updated_at, transaction_id, metadata
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+		INSERT INTO payments (` + paymentColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+		ON CONFLICT (merchant_id, livemode, idempotency_key) WHERE idempotency_key IS NOT NULL DO NOTHING`
 
 	metadataJSON, err := json.Marshal(payment.Metadata)
 	if err != nil {
-		logger.Error("Failed to marshal metadata: %v", err)
-		return err
+		logger.ErrorContext(ctx, "Failed to marshal metadata", "error", err)
+		return false, err
 	}
-	_, err = r.db.Exec(
+	result, err := r.db.ExecContext(
+		ctx,
 		query,
 		payment.ID,
 		payment.Amount,
@@ -65,114 +86,179 @@ func (r *PaymentRepository) Create(payment *model.Payment) error {
 		payment.UpdatedAt,
 		payment.TransactionID,
 		metadataJSON,
+		payment.ScheduledAt,
+		nullString(payment.CreatedBy),
+		payment.MerchantID,
+		payment.Livemode,
+		nullString(payment.IdempotencyKey),
 	)
 
 	if err != nil {
-		logger.Error("Failed to execute insert query: %v", err)
-		return fmt.Errorf("error creating payment; %w", err)
+		logger.ErrorContext(ctx, "Failed to execute insert query", "error", err)
+		return false, fmt.Errorf("error creating payment; %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("error creating payment; %w", err)
+	}
+	if rows == 0 {
+		logger.InfoContext(ctx, "Payment already exists for idempotency key", "idempotency_key", payment.IdempotencyKey)
+		return false, nil
 	}
 
-	logger.Info("Successfully created payment: ID=%s", payment.ID)
-	return nil
+	logger.InfoContext(ctx, "Successfully created payment", "payment_id", payment.ID)
+	return true, nil
 }
 
-func (r *PaymentRepository) FindByID(id string) (*model.Payment, error) {
-	logger.Info("Executing FindByID query for ID: %s", id)
+func (r *PaymentRepository) FindByID(ctx context.Context, merchantID string, id string) (_ *model.Payment, err error) {
+	ctx, span := startQuerySpan(ctx, "PaymentRepository.FindByID", "SELECT", "payments")
+	defer tracing.End(span, &err)
+
+	logger.InfoContext(ctx, "Executing FindByID query", "payment_id", id)
 
 	query := `
-		SELECT id, amount, currency, status, description, customer_id, 
-				created_at, updated_at, transaction_id, metadata
+		SELECT ` + paymentColumns + `
 		FROM payments
-		WHERE id = $1`
-
-	var payment model.Payment
-	var metadataBytes []byte
+		WHERE id = $1 AND merchant_id = $2`
 
-	err := r.db.QueryRow(query, id).Scan(
-		&payment.ID,
-		&payment.Amount,
-		&payment.Currency,
-		&payment.Status,
-		&payment.Description,
-		&payment.CustomerID,
-		&payment.CreatedAt,
-		&payment.UpdatedAt,
-		&payment.TransactionID,
-		&metadataBytes,
-	)
+	payment, err := scanPayment(r.db.QueryRowContext(ctx, query, id, merchantID))
 
 	if err == sql.ErrNoRows {
-		logger.Error("Payment not found; %s", id)
+		logger.ErrorContext(ctx, "Payment not found", "payment_id", id)
 		return nil, model.NewNotFoundError(("payment not found"))
 	}
 
 	if err != nil {
-		logger.Error("Database error; %v", err)
+		logger.ErrorContext(ctx, "Database error", "error", err)
 		return nil, fmt.Errorf("error finding payment: %w", err)
 	}
 
-	if err := json.Unmarshal(metadataBytes, &payment.Metadata); err != nil {
-		logger.Error("Failed to unmarshal metadata; %v", err)
-		return nil, err
+	logger.DebugContext(ctx, "Successfully found payment", "payment", payment)
+	return payment, nil
+}
+
+func (r *PaymentRepository) FindByTransactionID(ctx context.Context, transactionID string) (_ *model.Payment, err error) {
+	ctx, span := startQuerySpan(ctx, "PaymentRepository.FindByTransactionID", "SELECT", "payments")
+	defer tracing.End(span, &err)
+
+	logger.InfoContext(ctx, "Executing FindByTransactionID query", "transaction_id", transactionID)
+
+	query := `
+		SELECT ` + paymentColumns + `
+		FROM payments
+		WHERE transaction_id = $1`
+
+	payment, err := scanPayment(r.db.QueryRowContext(ctx, query, transactionID))
+	if err == sql.ErrNoRows {
+		return nil, model.NewNotFoundError("payment not found")
+	}
+	if err != nil {
+		logger.ErrorContext(ctx, "Database error", "error", err)
+		return nil, fmt.Errorf("error finding payment: %w", err)
 	}
+	return payment, nil
+}
 
-	logger.Debug("Successfully found payment: %+v", payment)
-	return &payment, nil
+// FindByIdempotencyKey returns the payment the merchant created with key in
+// the given mode.
+func (r *PaymentRepository) FindByIdempotencyKey(ctx context.Context, merchantID string, livemode bool, key string) (_ *model.Payment, err error) {
+	ctx, span := startQuerySpan(ctx, "PaymentRepository.FindByIdempotencyKey", "SELECT", "payments")
+	defer tracing.End(span, &err)
+
+	logger.InfoContext(ctx, "Executing FindByIdempotencyKey query", "idempotency_key", key)
+
+	query := `
+		SELECT ` + paymentColumns + `
+		FROM payments
+		WHERE merchant_id = $1 AND livemode = $2 AND idempotency_key = $3`
+
+	payment, err := scanPayment(r.db.QueryRowContext(ctx, query, merchantID, livemode, key))
+	if err == sql.ErrNoRows {
+		return nil, model.NewNotFoundError("payment not found")
+	}
+	if err != nil {
+		logger.ErrorContext(ctx, "Database error", "error", err)
+		return nil, fmt.Errorf("error finding payment: %w", err)
+	}
+	return payment, nil
 }
 
-func (r *PaymentRepository) List(limit int, offset int) ([]*model.Payment, error) {
-	logger.Info("Executing List query with limit=%d, offset=%d", limit, offset)
+func (r *PaymentRepository) List(ctx context.Context, merchantID string, livemode bool, limit int, offset int) (_ []*model.Payment, err error) {
+	ctx, span := startQuerySpan(ctx, "PaymentRepository.List", "SELECT", "payments")
+	defer tracing.End(span, &err)
+
+	logger.InfoContext(ctx, "Executing List query", "livemode", livemode, "limit", limit, "offset", offset)
 
 	query := `
-		SELECT id, amount, currency, status, description, customer_id,
-			  created_at, updated_at, transaction_id, metadata
+		SELECT ` + paymentColumns + `
 		FROM payments
+		WHERE merchant_id = $1 AND livemode = $2
 		ORDER BY created_at DESC
-		LIMIT $1 OFFSET $2`
+		LIMIT $3 OFFSET $4`
 
-	rows, err := r.db.Query(query, limit, offset)
+	payments, err := r.query(ctx, query, merchantID, livemode, limit, offset)
 	if err != nil {
-		logger.Error("Failed to execute list query: %v", err)
-		return nil, fmt.Errorf("error listing payments: %w", err)
+		return nil, err
 	}
-	defer rows.Close()
 
-	var payments []*model.Payment
-	for rows.Next() {
-		var payment model.Payment
-		var metadataBytes []byte
-		err := rows.Scan(
-			&payment.ID,
-			&payment.Amount,
-			&payment.Currency,
-			&payment.Status,
-			&payment.Description,
-			&payment.CustomerID,
-			&payment.CreatedAt,
-			&payment.UpdatedAt,
-			&payment.TransactionID,
-			&metadataBytes,
-		)
+	logger.InfoContext(ctx, "Successfully retrieved payments", "count", len(payments))
+	return payments, nil
+}
 
-		if err != nil {
-			logger.Error("Failed to scan payment row: %v", err)
-			return nil, fmt.Errorf("error scanning payment row: %w", err)
-		}
+// ClaimDueScheduled moves up to limit scheduled payments whose scheduled_at
+// has passed into the processing status, leases them until claimedUntil and
+// returns them. Rows locked by another replica are skipped, so each payment
+// is claimed by one replica; Update releases the lease, and payments whose
+// lease expired, e.g. because the replica crashed, are claimed again.
+func (r *PaymentRepository) ClaimDueScheduled(ctx context.Context, now time.Time, claimedUntil time.Time, limit int) (_ []*model.Payment, err error) {
+	ctx, span := startQuerySpan(ctx, "PaymentRepository.ClaimDueScheduled", "UPDATE", "payments")
+	defer tracing.End(span, &err)
 
-		if err := json.Unmarshal(metadataBytes, &payment.Metadata); err != nil {
-			logger.Error("Failed to unmarshal metadata: %v", err)
-			return nil, fmt.Errorf("error unmarshaling metadata: %w", err)
-		}
+	query := `
+		UPDATE payments
+		SET status = 'processing',
+			updated_at = $1,
+			claimed_until = $2
+		WHERE id IN (
+			SELECT id
+			FROM payments
+			WHERE (status = 'scheduled' AND scheduled_at <= $1)
+			   OR (status = 'processing' AND claimed_until <= $1)
+			ORDER BY scheduled_at
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED)
+		RETURNING ` + paymentColumns
+
+	return r.query(ctx, query, now, claimedUntil, limit)
+}
+
+func (r *PaymentRepository) Update(ctx context.Context, payment *model.Payment) error {
+	logger.InfoContext(ctx, "Updating payment", "payment_id", payment.ID)
 
-		payments = append(payments, &payment)
+	updated, err := r.update(ctx, payment, "")
+	if err != nil {
+		return err
+	}
+	if !updated {
+		logger.ErrorContext(ctx, "Payment not found for update", "payment_id", payment.ID)
+		return model.NewNotFoundError("payment not found")
 	}
 
-	logger.Info("Successfully retrieved %d payments", len(payments))
-	return payments, nil
+	logger.InfoContext(ctx, "Successfully updated payment", "payment_id", payment.ID)
+	return nil
 }
 
-func (r *PaymentRepository) Update(payment *model.Payment) error {
-	logger.Info("Updating payment: ID=%s", payment.ID)
+// UpdateIfStatus persists payment only while the stored row still has the
+// expected status and reports whether the update was applied.
+func (r *PaymentRepository) UpdateIfStatus(ctx context.Context, payment *model.Payment, expected model.PaymentStatus) (bool, error) {
+	logger.InfoContext(ctx, "Updating payment if status matches", "payment_id", payment.ID, "expected_status", expected)
+
+	return r.update(ctx, payment, expected)
+}
+
+func (r *PaymentRepository) update(ctx context.Context, payment *model.Payment, expected model.PaymentStatus) (_ bool, err error) {
+	ctx, span := startQuerySpan(ctx, "PaymentRepository.Update", "UPDATE", "payments")
+	defer tracing.End(span, &err)
 
 	query := `
 		UPDATE payments
@@ -183,37 +269,104 @@ func (r *PaymentRepository) Update(payment *model.Payment) error {
 			customer_id = $5,
 			updated_at = $6,
 			transaction_id = $7,
-			metadata = $8
-		WHERE id = $9`
+			metadata = $8,
+			scheduled_at = $9,
+			claimed_until = NULL
+		WHERE id = $10 AND merchant_id = $12 AND ($11 = '' OR status = $11)`
+
+	metadataJSON, err := json.Marshal(payment.Metadata)
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to marshal metadata", "error", err)
+		return false, err
+	}
 
-	result, err := r.db.Exec(
+	payment.UpdatedAt = time.Now()
+	result, err := r.db.ExecContext(
+		ctx,
 		query,
 		payment.Amount,
 		payment.Currency,
 		payment.Status,
 		payment.Description,
 		payment.CustomerID,
-		time.Now(),
+		payment.UpdatedAt,
 		payment.TransactionID,
-		payment.Metadata,
+		metadataJSON,
+		payment.ScheduledAt,
 		payment.ID,
+		string(expected),
+		payment.MerchantID,
 	)
 
 	if err != nil {
-		logger.Error("Failed to execute update query: %v", err)
-		return fmt.Errorf("error getting rows affected: %w", err)
+		logger.ErrorContext(ctx, "Failed to execute update query", "error", err)
+		return false, fmt.Errorf("error updating payment: %w", err)
 	}
 
 	rows, err := result.RowsAffected()
 	if err != nil {
-		logger.Error("Failed to get affected rows: %v", err)
-		return fmt.Errorf("error getting rows affected: %w", err)
+		logger.ErrorContext(ctx, "Failed to get affected rows", "error", err)
+		return false, fmt.Errorf("error getting rows affected: %w", err)
 	}
-	if rows == 0 {
-		logger.Error("Payment not found for update: %s", payment.ID)
-		return model.NewNotFoundError("payment not found")
+
+	return rows > 0, nil
+}
+
+func (r *PaymentRepository) query(ctx context.Context, query string, args ...interface{}) ([]*model.Payment, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to execute list query", "error", err)
+		return nil, fmt.Errorf("error listing payments: %w", err)
 	}
+	defer rows.Close()
 
-	logger.Info("Successfully updated payment: ID=%s", payment.ID)
-	return nil
+	var payments []*model.Payment
+	for rows.Next() {
+		payment, err := scanPayment(rows)
+		if err != nil {
+			logger.ErrorContext(ctx, "Failed to scan payment row", "error", err)
+			return nil, fmt.Errorf("error scanning payment row: %w", err)
+		}
+
+		payments = append(payments, payment)
+	}
+
+	return payments, rows.Err()
+}
+
+func scanPayment(row rowScanner) (*model.Payment, error) {
+	var payment model.Payment
+	var metadataBytes []byte
+	var createdBy sql.NullString
+	var idempotencyKey sql.NullString
+
+	err := row.Scan(
+		&payment.ID,
+		&payment.Amount,
+		&payment.Currency,
+		&payment.Status,
+		&payment.Description,
+		&payment.CustomerID,
+		&payment.CreatedAt,
+		&payment.UpdatedAt,
+		&payment.TransactionID,
+		&metadataBytes,
+		&payment.ScheduledAt,
+		&createdBy,
+		&payment.MerchantID,
+		&payment.Livemode,
+		&idempotencyKey,
+	)
+	if err != nil {
+		return nil, err
+	}
+	payment.CreatedBy = createdBy.String
+	payment.IdempotencyKey = idempotencyKey.String
+
+	if err := json.Unmarshal(metadataBytes, &payment.Metadata); err != nil {
+		logger.Error("Failed to unmarshal metadata", "error", err)
+		return nil, fmt.Errorf("error unmarshaling metadata: %w", err)
+	}
+
+	return &payment, nil
 }
@@ -0,0 +1,149 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"GO-API/internal/domain/model"
+	"GO-API/internal/pkg/logger"
+)
+
+type PlanRepository struct {
+	db *sql.DB
+}
+
+func NewPlanRepository(db *sql.DB) *PlanRepository {
+	return &PlanRepository{
+		db: db,
+	}
+}
+
+const createPlansTableSQL = `
+CREATE TABLE IF NOT EXISTS plans (
+	id TEXT PRIMARY KEY,
+	name TEXT NOT NULL,
+	amount BIGINT NOT NULL,
+	currency TEXT NOT NULL,
+	interval TEXT NOT NULL,
+	interval_count INTEGER NOT NULL,
+	trial_period_days INTEGER NOT NULL DEFAULT 0,
+	created_at TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP NOT NULL);
+ALTER TABLE plans ADD COLUMN IF NOT EXISTS merchant_id TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE plans ADD COLUMN IF NOT EXISTS livemode BOOLEAN NOT NULL DEFAULT TRUE;`
+
+func (r *PlanRepository) InitTable() error {
+	_, err := r.db.Exec(createPlansTableSQL)
+	return err
+}
+
+func (r *PlanRepository) Create(ctx context.Context, plan *model.Plan) error {
+	logger.InfoContext(ctx, "Creating plan", "plan_id", plan.ID, "amount", plan.Amount, "currency", plan.Currency)
+
+	query := `
+		INSERT INTO plans (
+			id, name, amount, currency, interval, interval_count,
+			trial_period_days, created_at, updated_at, merchant_id, livemode
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
+
+	_, err := r.db.ExecContext(
+		ctx,
+		query,
+		plan.ID,
+		plan.Name,
+		plan.Amount,
+		plan.Currency,
+		plan.Interval,
+		plan.IntervalCount,
+		plan.TrialPeriodDays,
+		plan.CreatedAt,
+		plan.UpdatedAt,
+		plan.MerchantID,
+		plan.Livemode,
+	)
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to execute insert query", "error", err)
+		return fmt.Errorf("error creating plan: %w", err)
+	}
+
+	return nil
+}
+
+func (r *PlanRepository) FindByID(ctx context.Context, merchantID string, livemode bool, id string) (*model.Plan, error) {
+	logger.InfoContext(ctx, "Executing FindByID query", "plan_id", id)
+
+	query := `
+		SELECT id, name, amount, currency, interval, interval_count,
+			trial_period_days, created_at, updated_at, merchant_id, livemode
+		FROM plans
+		WHERE id = $1 AND merchant_id = $2 AND livemode = $3`
+
+	plan, err := scanPlan(r.db.QueryRowContext(ctx, query, id, merchantID, livemode))
+	if err == sql.ErrNoRows {
+		logger.ErrorContext(ctx, "Plan not found", "plan_id", id)
+		return nil, model.NewNotFoundError("plan not found")
+	}
+	if err != nil {
+		logger.ErrorContext(ctx, "Database error", "error", err)
+		return nil, fmt.Errorf("error finding plan: %w", err)
+	}
+
+	return plan, nil
+}
+
+func (r *PlanRepository) List(ctx context.Context, merchantID string, livemode bool, limit int, offset int) ([]*model.Plan, error) {
+	logger.InfoContext(ctx, "Executing plan List query", "livemode", livemode, "limit", limit, "offset", offset)
+
+	query := `
+		SELECT id, name, amount, currency, interval, interval_count,
+			trial_period_days, created_at, updated_at, merchant_id, livemode
+		FROM plans
+		WHERE merchant_id = $1 AND livemode = $2
+		ORDER BY created_at DESC
+		LIMIT $3 OFFSET $4`
+
+	rows, err := r.db.QueryContext(ctx, query, merchantID, livemode, limit, offset)
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to execute list query", "error", err)
+		return nil, fmt.Errorf("error listing plans: %w", err)
+	}
+	defer rows.Close()
+
+	var plans []*model.Plan
+	for rows.Next() {
+		plan, err := scanPlan(rows)
+		if err != nil {
+			logger.ErrorContext(ctx, "Failed to scan plan row", "error", err)
+			return nil, fmt.Errorf("error scanning plan row: %w", err)
+		}
+		plans = append(plans, plan)
+	}
+
+	return plans, rows.Err()
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanPlan(row rowScanner) (*model.Plan, error) {
+	var plan model.Plan
+	err := row.Scan(
+		&plan.ID,
+		&plan.Name,
+		&plan.Amount,
+		&plan.Currency,
+		&plan.Interval,
+		&plan.IntervalCount,
+		&plan.TrialPeriodDays,
+		&plan.CreatedAt,
+		&plan.UpdatedAt,
+		&plan.MerchantID,
+		&plan.Livemode,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &plan, nil
+}
@@ -0,0 +1,92 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"GO-API/internal/pkg/logger"
+	"GO-API/internal/pkg/ratelimit"
+)
+
+// RateLimitStore keeps token buckets in Postgres so that limits hold across
+// replicas.
+type RateLimitStore struct {
+	db *sql.DB
+}
+
+func NewRateLimitStore(db *sql.DB) *RateLimitStore {
+	return &RateLimitStore{
+		db: db,
+	}
+}
+
+const createRateLimitBucketsTableSQL = `
+CREATE TABLE IF NOT EXISTS rate_limit_buckets (
+	key TEXT PRIMARY KEY,
+	tokens DOUBLE PRECISION NOT NULL,
+	updated_at TIMESTAMP NOT NULL,
+	expires_at TIMESTAMP NOT NULL);`
+
+func (s *RateLimitStore) InitTable() error {
+	_, err := s.db.Exec(createRateLimitBucketsTableSQL)
+	return err
+}
+
+// Take locks the bucket row for the duration of the refill-and-consume step so
+// concurrent requests from different replicas are counted exactly once.
+func (s *RateLimitStore) Take(ctx context.Context, key string, limit ratelimit.Limit, now time.Time) (ratelimit.Result, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return ratelimit.Result{}, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO rate_limit_buckets (key, tokens, updated_at, expires_at)
+		VALUES ($1, $2, $3, $3)
+		ON CONFLICT (key) DO NOTHING`, key, float64(limit.Requests), now)
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to execute insert query", "error", err)
+		return ratelimit.Result{}, fmt.Errorf("error creating rate limit bucket: %w", err)
+	}
+
+	var bucket ratelimit.Bucket
+	err = tx.QueryRowContext(ctx, `
+		SELECT tokens, updated_at
+		FROM rate_limit_buckets
+		WHERE key = $1
+		FOR UPDATE`, key).Scan(&bucket.Tokens, &bucket.UpdatedAt)
+	if err != nil {
+		logger.ErrorContext(ctx, "Database error", "error", err)
+		return ratelimit.Result{}, fmt.Errorf("error loading rate limit bucket: %w", err)
+	}
+
+	result := limit.Take(&bucket, now)
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE rate_limit_buckets
+		SET tokens = $2, updated_at = $3, expires_at = $4
+		WHERE key = $1`, key, bucket.Tokens, bucket.UpdatedAt, now.Add(result.Reset))
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to execute update query", "error", err)
+		return ratelimit.Result{}, fmt.Errorf("error updating rate limit bucket: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return ratelimit.Result{}, fmt.Errorf("error committing rate limit bucket: %w", err)
+	}
+	return result, nil
+}
+
+// DeleteExpired drops buckets that have refilled completely and are
+// equivalent to a missing row.
+func (s *RateLimitStore) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM rate_limit_buckets WHERE expires_at < $1`, now)
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to execute delete query", "error", err)
+		return 0, fmt.Errorf("error deleting expired rate limit buckets: %w", err)
+	}
+	return result.RowsAffected()
+}
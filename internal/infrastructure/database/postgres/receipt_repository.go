@@ -0,0 +1,191 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"GO-API/internal/domain/model"
+	"GO-API/internal/pkg/logger"
+)
+
+type ReceiptRepository struct {
+	db *sql.DB
+}
+
+func NewReceiptRepository(db *sql.DB) *ReceiptRepository {
+	return &ReceiptRepository{
+		db: db,
+	}
+}
+
+// Receipts issued before livemode was stored take it from their payment. The
+// backfill runs only when the column is added, since new receipts are created
+// with it.
+const createReceiptsTableSQL = `
+CREATE SEQUENCE IF NOT EXISTS receipt_number_seq;
+CREATE TABLE IF NOT EXISTS receipts (
+	id TEXT PRIMARY KEY,
+	receipt_number TEXT NOT NULL UNIQUE,
+	payment_id TEXT NOT NULL UNIQUE REFERENCES payments(id),
+	transaction_id TEXT NOT NULL,
+	amount BIGINT NOT NULL,
+	currency TEXT NOT NULL,
+	recipient_name TEXT NOT NULL,
+	proviso TEXT NOT NULL,
+	issuer JSONB NOT NULL,
+	paid_at TIMESTAMP NOT NULL,
+	issued_at TIMESTAMP NOT NULL,
+	reissue_count INTEGER NOT NULL DEFAULT 0,
+	reissued_at TIMESTAMP);
+ALTER TABLE receipts ADD COLUMN IF NOT EXISTS merchant_id TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE receipts ADD COLUMN IF NOT EXISTS reissue_reason TEXT NOT NULL DEFAULT '';
+DO $$
+BEGIN
+	IF NOT EXISTS (
+		SELECT 1 FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name = 'receipts' AND column_name = 'livemode'
+	) THEN
+		ALTER TABLE receipts ADD COLUMN livemode BOOLEAN NOT NULL DEFAULT TRUE;
+		UPDATE receipts SET livemode = payments.livemode
+			FROM payments
+			WHERE receipts.payment_id = payments.id AND NOT payments.livemode;
+	END IF;
+END $$;`
+
+func (r *ReceiptRepository) InitTable() error {
+	_, err := r.db.Exec(createReceiptsTableSQL)
+	return err
+}
+
+func (r *ReceiptRepository) NextReceiptNumber(ctx context.Context, issueDate time.Time) (string, error) {
+	var seq int64
+	if err := r.db.QueryRowContext(ctx, `SELECT nextval('receipt_number_seq')`).Scan(&seq); err != nil {
+		logger.ErrorContext(ctx, "Failed to allocate receipt number", "error", err)
+		return "", fmt.Errorf("error allocating receipt number: %w", err)
+	}
+	return fmt.Sprintf("RCT-%s-%06d", issueDate.Format("2006"), seq), nil
+}
+
+// Create inserts the receipt unless one has already been issued for its
+// payment and reports whether it was inserted. The unique payment_id makes
+// concurrent first issues race safely, with exactly one of them succeeding.
+func (r *ReceiptRepository) Create(ctx context.Context, receipt *model.Receipt) (bool, error) {
+	logger.InfoContext(ctx, "Creating receipt", "receipt_id", receipt.ID, "number", receipt.ReceiptNumber)
+
+	issuerJSON, err := json.Marshal(receipt.Issuer)
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to marshal issuer", "error", err)
+		return false, err
+	}
+
+	query := `
+		INSERT INTO receipts (
+			id, receipt_number, payment_id, transaction_id, amount, currency,
+			recipient_name, proviso, issuer, paid_at, issued_at, reissue_count, reissued_at, merchant_id, livemode
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+		ON CONFLICT (payment_id) DO NOTHING`
+
+	result, err := r.db.ExecContext(
+		ctx,
+		query,
+		receipt.ID,
+		receipt.ReceiptNumber,
+		receipt.PaymentID,
+		receipt.TransactionID,
+		receipt.Amount,
+		receipt.Currency,
+		receipt.RecipientName,
+		receipt.Proviso,
+		issuerJSON,
+		receipt.PaidAt,
+		receipt.IssuedAt,
+		receipt.ReissueCount,
+		receipt.ReissuedAt,
+		receipt.MerchantID,
+		receipt.Livemode,
+	)
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to execute insert query", "error", err)
+		return false, fmt.Errorf("error creating receipt: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("error getting rows affected: %w", err)
+	}
+	return rows > 0, nil
+}
+
+func (r *ReceiptRepository) FindByPaymentID(ctx context.Context, merchantID string, livemode bool, paymentID string) (*model.Receipt, error) {
+	logger.InfoContext(ctx, "Executing receipt FindByPaymentID query", "payment_id", paymentID)
+
+	query := `
+		SELECT id, receipt_number, payment_id, transaction_id, amount, currency,
+			recipient_name, proviso, issuer, paid_at, issued_at, reissue_count, reissued_at, merchant_id,
+			reissue_reason, livemode
+		FROM receipts
+		WHERE payment_id = $1 AND merchant_id = $2 AND livemode = $3`
+
+	var receipt model.Receipt
+	var issuerJSON []byte
+	err := r.db.QueryRowContext(ctx, query, paymentID, merchantID, livemode).Scan(
+		&receipt.ID,
+		&receipt.ReceiptNumber,
+		&receipt.PaymentID,
+		&receipt.TransactionID,
+		&receipt.Amount,
+		&receipt.Currency,
+		&receipt.RecipientName,
+		&receipt.Proviso,
+		&issuerJSON,
+		&receipt.PaidAt,
+		&receipt.IssuedAt,
+		&receipt.ReissueCount,
+		&receipt.ReissuedAt,
+		&receipt.MerchantID,
+		&receipt.ReissueReason,
+		&receipt.Livemode,
+	)
+	if err == sql.ErrNoRows {
+		return nil, model.NewNotFoundError("receipt not found")
+	}
+	if err != nil {
+		logger.ErrorContext(ctx, "Database error", "error", err)
+		return nil, fmt.Errorf("error finding receipt: %w", err)
+	}
+
+	if err := json.Unmarshal(issuerJSON, &receipt.Issuer); err != nil {
+		return nil, fmt.Errorf("error unmarshaling issuer: %w", err)
+	}
+
+	return &receipt, nil
+}
+
+// Reissue counts another reissue of the receipt and records when and why it
+// was made. The count is incremented in the database so that concurrent
+// reissues are all counted.
+func (r *ReceiptRepository) Reissue(ctx context.Context, receipt *model.Receipt) error {
+	logger.InfoContext(ctx, "Reissuing receipt", "receipt_id", receipt.ID)
+
+	query := `
+		UPDATE receipts
+		SET reissue_count = reissue_count + 1,
+			reissued_at = $1,
+			reissue_reason = $2
+		WHERE id = $3 AND merchant_id = $4
+		RETURNING reissue_count`
+
+	err := r.db.QueryRowContext(ctx, query, receipt.ReissuedAt, receipt.ReissueReason, receipt.ID, receipt.MerchantID).Scan(&receipt.ReissueCount)
+	if err == sql.ErrNoRows {
+		return model.NewNotFoundError("receipt not found")
+	}
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to execute update query", "error", err)
+		return fmt.Errorf("error reissuing receipt: %w", err)
+	}
+
+	return nil
+}
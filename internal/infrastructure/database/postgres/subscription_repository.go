@@ -0,0 +1,249 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"GO-API/internal/domain/model"
+	"GO-API/internal/pkg/logger"
+)
+
+type SubscriptionRepository struct {
+	db *sql.DB
+}
+
+func NewSubscriptionRepository(db *sql.DB) *SubscriptionRepository {
+	return &SubscriptionRepository{
+		db: db,
+	}
+}
+
+const createSubscriptionsTableSQL = `
+CREATE TABLE IF NOT EXISTS subscriptions (
+	id TEXT PRIMARY KEY,
+	customer_id TEXT NOT NULL,
+	plan_id TEXT NOT NULL REFERENCES plans(id),
+	payment_method TEXT NOT NULL,
+	status TEXT NOT NULL,
+	billing_anchor TIMESTAMP NOT NULL,
+	current_period_start TIMESTAMP NOT NULL,
+	current_period_end TIMESTAMP NOT NULL,
+	trial_end TIMESTAMP,
+	cancel_at_period_end BOOLEAN NOT NULL DEFAULT FALSE,
+	canceled_at TIMESTAMP,
+	retry_count INTEGER NOT NULL DEFAULT 0,
+	next_retry_at TIMESTAMP,
+	last_payment_id TEXT,
+	created_at TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP NOT NULL);
+CREATE INDEX IF NOT EXISTS subscriptions_due_idx ON subscriptions (status, current_period_end);
+ALTER TABLE subscriptions ADD COLUMN IF NOT EXISTS merchant_id TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE subscriptions ADD COLUMN IF NOT EXISTS livemode BOOLEAN NOT NULL DEFAULT TRUE;
+ALTER TABLE subscriptions ADD COLUMN IF NOT EXISTS claimed_until TIMESTAMP;`
+
+const subscriptionColumns = `id, customer_id, plan_id, payment_method, status, billing_anchor,
+	current_period_start, current_period_end, trial_end, cancel_at_period_end,
+	canceled_at, retry_count, next_retry_at, last_payment_id, created_at, updated_at, merchant_id, livemode`
+
+func (r *SubscriptionRepository) InitTable() error {
+	_, err := r.db.Exec(createSubscriptionsTableSQL)
+	return err
+}
+
+func (r *SubscriptionRepository) Create(ctx context.Context, subscription *model.Subscription) error {
+	logger.InfoContext(ctx, "Creating subscription", "subscription_id", subscription.ID, "plan_id", subscription.PlanID)
+
+	query := `
+		INSERT INTO subscriptions (` + subscriptionColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)`
+
+	_, err := r.db.ExecContext(
+		ctx,
+		query,
+		subscription.ID,
+		subscription.CustomerID,
+		subscription.PlanID,
+		subscription.PaymentMethod,
+		subscription.Status,
+		subscription.BillingAnchor,
+		subscription.CurrentPeriodStart,
+		subscription.CurrentPeriodEnd,
+		subscription.TrialEnd,
+		subscription.CancelAtPeriodEnd,
+		subscription.CanceledAt,
+		subscription.RetryCount,
+		subscription.NextRetryAt,
+		nullString(subscription.LastPaymentID),
+		subscription.CreatedAt,
+		subscription.UpdatedAt,
+		subscription.MerchantID,
+		subscription.Livemode,
+	)
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to execute insert query", "error", err)
+		return fmt.Errorf("error creating subscription: %w", err)
+	}
+
+	return nil
+}
+
+func (r *SubscriptionRepository) FindByID(ctx context.Context, merchantID string, id string) (*model.Subscription, error) {
+	logger.InfoContext(ctx, "Executing FindByID query", "subscription_id", id)
+
+	query := `SELECT ` + subscriptionColumns + ` FROM subscriptions WHERE id = $1 AND merchant_id = $2`
+
+	subscription, err := scanSubscription(r.db.QueryRowContext(ctx, query, id, merchantID))
+	if err == sql.ErrNoRows {
+		logger.ErrorContext(ctx, "Subscription not found", "subscription_id", id)
+		return nil, model.NewNotFoundError("subscription not found")
+	}
+	if err != nil {
+		logger.ErrorContext(ctx, "Database error", "error", err)
+		return nil, fmt.Errorf("error finding subscription: %w", err)
+	}
+
+	return subscription, nil
+}
+
+func (r *SubscriptionRepository) List(ctx context.Context, merchantID string, livemode bool, limit int, offset int) ([]*model.Subscription, error) {
+	logger.InfoContext(ctx, "Executing subscription List query", "livemode", livemode, "limit", limit, "offset", offset)
+
+	query := `
+		SELECT ` + subscriptionColumns + `
+		FROM subscriptions
+		WHERE merchant_id = $1 AND livemode = $2
+		ORDER BY created_at DESC
+		LIMIT $3 OFFSET $4`
+
+	return r.query(ctx, query, merchantID, livemode, limit, offset)
+}
+
+// ClaimDue leases up to limit subscriptions whose period has ended or whose
+// retry is due until claimedUntil and returns them. Rows locked or leased by
+// another replica are skipped, so each renewal is attempted by one replica;
+// Update releases the lease, and a lease left by a crashed replica expires.
+func (r *SubscriptionRepository) ClaimDue(ctx context.Context, now time.Time, claimedUntil time.Time, limit int) ([]*model.Subscription, error) {
+	query := `
+		UPDATE subscriptions
+		SET claimed_until = $2
+		WHERE id IN (
+			SELECT id
+			FROM subscriptions
+			WHERE ((status = 'active' AND current_period_end <= $1)
+			    OR (status = 'past_due' AND next_retry_at <= $1))
+			  AND (claimed_until IS NULL OR claimed_until <= $1)
+			ORDER BY current_period_end
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED)
+		RETURNING ` + subscriptionColumns
+
+	return r.query(ctx, query, now, claimedUntil, limit)
+}
+
+func (r *SubscriptionRepository) Update(ctx context.Context, subscription *model.Subscription) error {
+	logger.InfoContext(ctx, "Updating subscription", "subscription_id", subscription.ID)
+
+	query := `
+		UPDATE subscriptions
+		SET status = $1,
+			current_period_start = $2,
+			current_period_end = $3,
+			cancel_at_period_end = $4,
+			canceled_at = $5,
+			retry_count = $6,
+			next_retry_at = $7,
+			last_payment_id = $8,
+			updated_at = $9,
+			claimed_until = NULL
+		WHERE id = $10 AND merchant_id = $11`
+
+	subscription.UpdatedAt = time.Now()
+	result, err := r.db.ExecContext(
+		ctx,
+		query,
+		subscription.Status,
+		subscription.CurrentPeriodStart,
+		subscription.CurrentPeriodEnd,
+		subscription.CancelAtPeriodEnd,
+		subscription.CanceledAt,
+		subscription.RetryCount,
+		subscription.NextRetryAt,
+		nullString(subscription.LastPaymentID),
+		subscription.UpdatedAt,
+		subscription.ID,
+		subscription.MerchantID,
+	)
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to execute update query", "error", err)
+		return fmt.Errorf("error updating subscription: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to get affected rows", "error", err)
+		return fmt.Errorf("error getting rows affected: %w", err)
+	}
+	if rows == 0 {
+		logger.ErrorContext(ctx, "Subscription not found for update", "subscription_id", subscription.ID)
+		return model.NewNotFoundError("subscription not found")
+	}
+
+	return nil
+}
+
+func (r *SubscriptionRepository) query(ctx context.Context, query string, args ...interface{}) ([]*model.Subscription, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to execute subscription query", "error", err)
+		return nil, fmt.Errorf("error querying subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	var subscriptions []*model.Subscription
+	for rows.Next() {
+		subscription, err := scanSubscription(rows)
+		if err != nil {
+			logger.ErrorContext(ctx, "Failed to scan subscription row", "error", err)
+			return nil, fmt.Errorf("error scanning subscription row: %w", err)
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+
+	return subscriptions, rows.Err()
+}
+
+func scanSubscription(row rowScanner) (*model.Subscription, error) {
+	var subscription model.Subscription
+	var lastPaymentID sql.NullString
+	err := row.Scan(
+		&subscription.ID,
+		&subscription.CustomerID,
+		&subscription.PlanID,
+		&subscription.PaymentMethod,
+		&subscription.Status,
+		&subscription.BillingAnchor,
+		&subscription.CurrentPeriodStart,
+		&subscription.CurrentPeriodEnd,
+		&subscription.TrialEnd,
+		&subscription.CancelAtPeriodEnd,
+		&subscription.CanceledAt,
+		&subscription.RetryCount,
+		&subscription.NextRetryAt,
+		&lastPaymentID,
+		&subscription.CreatedAt,
+		&subscription.UpdatedAt,
+		&subscription.MerchantID,
+		&subscription.Livemode,
+	)
+	if err != nil {
+		return nil, err
+	}
+	subscription.LastPaymentID = lastPaymentID.String
+	return &subscription, nil
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
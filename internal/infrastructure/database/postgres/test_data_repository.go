@@ -0,0 +1,84 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"GO-API/internal/domain/model"
+	"GO-API/internal/pkg/logger"
+)
+
+type TestDataRepository struct {
+	db *sql.DB
+}
+
+func NewTestDataRepository(db *sql.DB) *TestDataRepository {
+	return &TestDataRepository{
+		db: db,
+	}
+}
+
+func (r *TestDataRepository) DeleteTestData(ctx context.Context, merchantID string) (*model.TestDataDeletion, error) {
+	logger.InfoContext(ctx, "Deleting test data", "merchant_id", merchantID)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var result model.TestDataDeletion
+
+	// Receipts reference payments, so they go first.
+	result.Receipts, err = execCount(ctx, tx, `
+		DELETE FROM receipts
+		WHERE merchant_id = $1 AND NOT livemode`, merchantID)
+	if err != nil {
+		return nil, fmt.Errorf("error deleting test receipts: %w", err)
+	}
+
+	result.Payments, err = execCount(ctx, tx, `
+		DELETE FROM payments
+		WHERE merchant_id = $1 AND NOT livemode`, merchantID)
+	if err != nil {
+		return nil, fmt.Errorf("error deleting test payments: %w", err)
+	}
+
+	result.Subscriptions, err = execCount(ctx, tx, `
+		DELETE FROM subscriptions
+		WHERE merchant_id = $1 AND NOT livemode`, merchantID)
+	if err != nil {
+		return nil, fmt.Errorf("error deleting test subscriptions: %w", err)
+	}
+
+	// Plans are referenced by subscriptions, so they go after them.
+	result.Plans, err = execCount(ctx, tx, `
+		DELETE FROM plans
+		WHERE merchant_id = $1 AND NOT livemode`, merchantID)
+	if err != nil {
+		return nil, fmt.Errorf("error deleting test plans: %w", err)
+	}
+
+	result.Invoices, err = execCount(ctx, tx, `
+		DELETE FROM invoices
+		WHERE merchant_id = $1 AND NOT livemode`, merchantID)
+	if err != nil {
+		return nil, fmt.Errorf("error deleting test invoices: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing test data deletion: %w", err)
+	}
+
+	logger.InfoContext(ctx, "Deleted test data", "merchant_id", merchantID, "payments", result.Payments, "receipts", result.Receipts, "subscriptions", result.Subscriptions, "plans", result.Plans, "invoices", result.Invoices)
+	return &result, nil
+}
+
+func execCount(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) (int64, error) {
+	res, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
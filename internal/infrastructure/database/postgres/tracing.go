@@ -0,0 +1,20 @@
+package postgres
+
+import (
+	"context"
+
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"GO-API/internal/pkg/tracing"
+)
+
+// startQuerySpan starts a span for a repository method that runs a single
+// SQL operation against table.
+func startQuerySpan(ctx context.Context, name, operation, table string) (context.Context, trace.Span) {
+	return tracing.Start(ctx, name,
+		semconv.DBSystemPostgreSQL,
+		semconv.DBOperationName(operation),
+		semconv.DBCollectionName(table),
+	)
+}
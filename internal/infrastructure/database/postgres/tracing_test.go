@@ -0,0 +1,30 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"GO-API/internal/pkg/tracing"
+	"GO-API/internal/pkg/tracing/tracingtest"
+)
+
+func TestStartQuerySpan(t *testing.T) {
+	exporter := tracingtest.Record(t)
+
+	ctx, parent := tracing.Start(context.Background(), "PaymentUseCase.GetPayment")
+	_, span := startQuerySpan(ctx, "PaymentRepository.FindByID", "SELECT", "payments")
+	span.End()
+	parent.End()
+
+	query := tracingtest.Span(t, exporter, "PaymentRepository.FindByID")
+	if query.Parent.SpanID() != parent.SpanContext().SpanID() {
+		t.Errorf("query span is not a child of the span in ctx")
+	}
+	tracingtest.AssertAttributes(t, query,
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.operation.name", "SELECT"),
+		attribute.String("db.collection.name", "payments"),
+	)
+}
@@ -0,0 +1,55 @@
+package processor
+
+import (
+	"encoding/json"
+	"time"
+
+	"GO-API/internal/domain/model"
+)
+
+type callbackPayload struct {
+	TransactionID string    `json:"transaction_id"`
+	Status        string    `json:"status"`
+	OccurredAt    time.Time `json:"occurred_at"`
+}
+
+// callbackStatuses maps the statuses acquirers report asynchronously, such as a
+// convenience store payment being paid or a bank transfer being received, to
+// payment statuses.
+var callbackStatuses = map[string]model.PaymentStatus{
+	"paid":      model.PaymentStatusCompleted,
+	"received":  model.PaymentStatusCompleted,
+	"captured":  model.PaymentStatusCompleted,
+	"failed":    model.PaymentStatusFailed,
+	"declined":  model.PaymentStatusFailed,
+	"expired":   model.PaymentStatusCanceled,
+	"canceled":  model.PaymentStatusCanceled,
+	"cancelled": model.PaymentStatusCanceled,
+}
+
+// ParseCallback decodes a callback body sent by the acquirer. The signature
+// has already been verified by the time it is called.
+func (p *PaymentProcessor) ParseCallback(body []byte) (*model.PaymentCallback, error) {
+	var payload callbackPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, model.NewValidationError("invalid callback body")
+	}
+	if payload.TransactionID == "" {
+		return nil, model.NewValidationError("transaction_id is required")
+	}
+
+	status, ok := callbackStatuses[payload.Status]
+	if !ok {
+		return nil, model.NewValidationError("unsupported callback status: " + payload.Status)
+	}
+
+	occurredAt := payload.OccurredAt
+	if occurredAt.IsZero() {
+		occurredAt = time.Now()
+	}
+	return &model.PaymentCallback{
+		TransactionID: payload.TransactionID,
+		Status:        status,
+		OccurredAt:    occurredAt,
+	}, nil
+}
@@ -0,0 +1,51 @@
+package processor
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"GO-API/internal/domain/model"
+	"GO-API/internal/gateway"
+	"GO-API/internal/pkg/metrics"
+	"GO-API/internal/pkg/tracing"
+)
+
+// Instrumented traces every call to the wrapped processor and records its
+// latency and errors under name.
+type Instrumented struct {
+	name string
+	next gateway.PaymentProcessor
+}
+
+func NewInstrumented(name string, next gateway.PaymentProcessor) *Instrumented {
+	return &Instrumented{
+		name: name,
+		next: next,
+	}
+}
+
+func (p *Instrumented) Process(ctx context.Context, payment *model.Payment) error {
+	return p.observe(ctx, "process", payment, p.next.Process)
+}
+
+func (p *Instrumented) Cancel(ctx context.Context, payment *model.Payment) error {
+	return p.observe(ctx, "cancel", payment, p.next.Cancel)
+}
+
+func (p *Instrumented) observe(ctx context.Context, operation string, payment *model.Payment,
+	call func(context.Context, *model.Payment) error) (err error) {
+	ctx, span := tracing.Start(ctx, "PaymentProcessor."+operation,
+		attribute.String("processor.name", p.name),
+		attribute.String("payment.id", payment.ID),
+		attribute.String("payment.method", payment.Metadata.PaymentMethod),
+	)
+	defer tracing.End(span, &err)
+
+	start := time.Now()
+	err = call(ctx, payment)
+	metrics.ObserveProcessorCall(p.name, operation, time.Since(start), err)
+	span.SetAttributes(attribute.String("payment.status", string(payment.Status)))
+	return err
+}
@@ -0,0 +1,74 @@
+package processor_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"GO-API/internal/domain/model"
+	"GO-API/internal/infrastructure/processor"
+	"GO-API/internal/pkg/tracing/tracingtest"
+)
+
+type failingProcessor struct {
+	err error
+}
+
+func (p failingProcessor) Process(ctx context.Context, payment *model.Payment) error {
+	return p.err
+}
+
+func (p failingProcessor) Cancel(ctx context.Context, payment *model.Payment) error {
+	return p.err
+}
+
+func TestInstrumentedProcessRecordsSpan(t *testing.T) {
+	exporter := tracingtest.Record(t)
+
+	payment := &model.Payment{
+		ID:       "pay_1",
+		Amount:   1000,
+		Status:   model.PaymentStatusPending,
+		Metadata: model.PaymentMetadata{PaymentMethod: "credit_card"},
+	}
+	p := processor.NewInstrumented("simulated", processor.NewSimulatedProcessor())
+	if err := p.Process(context.Background(), payment); err != nil {
+		t.Fatal(err)
+	}
+
+	span := tracingtest.Span(t, exporter, "PaymentProcessor.process")
+	if span.Status.Code == codes.Error {
+		t.Errorf("span status = error, want unset")
+	}
+	tracingtest.AssertAttributes(t, span,
+		attribute.String("processor.name", "simulated"),
+		attribute.String("payment.id", "pay_1"),
+		attribute.String("payment.method", "credit_card"),
+		attribute.String("payment.status", string(model.PaymentStatusCompleted)),
+	)
+}
+
+func TestInstrumentedCancelRecordsError(t *testing.T) {
+	exporter := tracingtest.Record(t)
+
+	p := processor.NewInstrumented("live", failingProcessor{err: errors.New("acquirer unavailable")})
+	err := p.Cancel(context.Background(), &model.Payment{ID: "pay_2"})
+	if err == nil {
+		t.Fatal("Cancel succeeded, want the processor's error")
+	}
+
+	span := tracingtest.Span(t, exporter, "PaymentProcessor.cancel")
+	if span.Status.Code != codes.Error || span.Status.Description != "acquirer unavailable" {
+		t.Errorf("span status = %v %q, want error %q", span.Status.Code, span.Status.Description, "acquirer unavailable")
+	}
+	if len(span.Events) == 0 || span.Events[0].Name != "exception" {
+		t.Errorf("span has no recorded exception event: %v", span.Events)
+	}
+	tracingtest.AssertAttributes(t, span,
+		attribute.String("processor.name", "live"),
+		attribute.String("payment.id", "pay_2"),
+	)
+}
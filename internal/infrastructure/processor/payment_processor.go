@@ -1,7 +1,10 @@
 package processor
 
 import (
+	"context"
+
 	"GO-API/internal/domain/model"
+	"GO-API/internal/pkg/logger"
 )
 
 type PaymentProcessor struct {
@@ -11,10 +14,42 @@ func NewPaymentProcessor() *PaymentProcessor {
 	return &PaymentProcessor{}
 }
 
-func (p *PaymentProcessor) Process(payment *model.Payment) error {
+func (p *PaymentProcessor) Process(ctx context.Context, payment *model.Payment) error {
+	if installment := payment.Metadata.Installment; installment != nil {
+		logger.InfoContext(ctx, "Processing payment with installment", "payment_id", payment.ID, "installment_code", InstallmentCode(installment.Type), "installment_count", installment.Count)
+	}
+
+	switch payment.Metadata.PaymentMethod {
+	case "convenience_store", "bank_transfer":
+		payment.Status = model.PaymentStatusProcessing
+	default:
+		payment.Status = model.PaymentStatusCompleted
+	}
 	return nil
 }
 
-func (p *PaymentProcessor) Cancel(payment *model.Payment) error {
+// Check reports whether the processor can take payments. It is used as a
+// readiness check; the processor runs in-process, so it always can.
+func (p *PaymentProcessor) Check(ctx context.Context) error {
 	return nil
 }
+
+func (p *PaymentProcessor) Cancel(ctx context.Context, payment *model.Payment) error {
+	payment.Status = model.PaymentStatusCanceled
+	return nil
+}
+
+// InstallmentCode maps an installment type to the JPO payment division code
+// card acquirers in Japan expect.
+func InstallmentCode(installmentType model.InstallmentType) string {
+	switch installmentType {
+	case model.InstallmentTypeInstallments:
+		return "61"
+	case model.InstallmentTypeBonus:
+		return "21"
+	case model.InstallmentTypeRevolving:
+		return "80"
+	default:
+		return "10"
+	}
+}
@@ -0,0 +1,37 @@
+package processor
+
+import (
+	"context"
+
+	"GO-API/internal/domain/model"
+	"GO-API/internal/gateway"
+)
+
+// Router sends live payments to the live processor and test mode payments to
+// the test processor, so test keys never reach a real acquirer.
+type Router struct {
+	live gateway.PaymentProcessor
+	test gateway.PaymentProcessor
+}
+
+func NewRouter(live, test gateway.PaymentProcessor) *Router {
+	return &Router{
+		live: live,
+		test: test,
+	}
+}
+
+func (r *Router) Process(ctx context.Context, payment *model.Payment) error {
+	return r.route(payment).Process(ctx, payment)
+}
+
+func (r *Router) Cancel(ctx context.Context, payment *model.Payment) error {
+	return r.route(payment).Cancel(ctx, payment)
+}
+
+func (r *Router) route(payment *model.Payment) gateway.PaymentProcessor {
+	if payment.Livemode {
+		return r.live
+	}
+	return r.test
+}
@@ -0,0 +1,42 @@
+package processor
+
+import (
+	"context"
+
+	"GO-API/internal/domain/model"
+	"GO-API/internal/pkg/logger"
+)
+
+// SimulatedProcessor handles test mode payments without contacting any
+// acquirer. It follows the same status transitions as the live processor,
+// except that amounts ending in 02 are declined so integrations can exercise
+// failures.
+type SimulatedProcessor struct {
+}
+
+func NewSimulatedProcessor() *SimulatedProcessor {
+	return &SimulatedProcessor{}
+}
+
+func (p *SimulatedProcessor) Process(ctx context.Context, payment *model.Payment) error {
+	logger.InfoContext(ctx, "Simulating payment", "payment_id", payment.ID, "amount", payment.Amount, "payment_method", payment.Metadata.PaymentMethod)
+
+	if payment.Amount%100 == 2 {
+		payment.Status = model.PaymentStatusFailed
+		return nil
+	}
+
+	switch payment.Metadata.PaymentMethod {
+	case "convenience_store", "bank_transfer":
+		payment.Status = model.PaymentStatusProcessing
+	default:
+		payment.Status = model.PaymentStatusCompleted
+	}
+	return nil
+}
+
+func (p *SimulatedProcessor) Cancel(ctx context.Context, payment *model.Payment) error {
+	logger.InfoContext(ctx, "Simulating cancellation of payment", "payment_id", payment.ID)
+	payment.Status = model.PaymentStatusCanceled
+	return nil
+}
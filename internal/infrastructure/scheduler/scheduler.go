@@ -0,0 +1,90 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"GO-API/internal/pkg/logger"
+)
+
+type Job func(ctx context.Context) error
+
+// Scheduler runs a job at a fixed interval in a background goroutine until
+// Stop is called. Runs never overlap: a slow run delays the next tick.
+type Scheduler struct {
+	name     string
+	interval time.Duration
+	job      Job
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	// heartbeat is the UnixNano time the scheduler started or a run last
+	// finished, or zero before Start.
+	heartbeat atomic.Int64
+}
+
+func New(name string, interval time.Duration, job Job) *Scheduler {
+	return &Scheduler{
+		name:     name,
+		interval: interval,
+		job:      job,
+	}
+}
+
+func (s *Scheduler) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.heartbeat.Store(time.Now().UnixNano())
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		logger.Info("Scheduler started", "scheduler", s.name, "interval", s.interval)
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		for {
+			s.run(ctx)
+
+			select {
+			case <-ctx.Done():
+				logger.Info("Scheduler stopped", "scheduler", s.name)
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (s *Scheduler) Stop() {
+	if s.cancel == nil {
+		return
+	}
+	s.cancel()
+	s.wg.Wait()
+}
+
+func (s *Scheduler) run(ctx context.Context) {
+	if err := s.job(ctx); err != nil {
+		logger.ErrorContext(ctx, "Scheduler job failed", "scheduler", s.name, "error", err)
+	}
+	s.heartbeat.Store(time.Now().UnixNano())
+}
+
+// Check is a heartbeat: it fails when no run has finished within three
+// intervals, which means the job is stuck or the scheduler has stopped.
+// Failed runs still count, since the job reports its own errors.
+func (s *Scheduler) Check(ctx context.Context) error {
+	last := s.heartbeat.Load()
+	if last == 0 {
+		return fmt.Errorf("scheduler %s is not running", s.name)
+	}
+	if since := time.Since(time.Unix(0, last)); since > 3*s.interval {
+		return fmt.Errorf("scheduler %s last ran %s ago", s.name, since.Round(time.Second))
+	}
+	return nil
+}
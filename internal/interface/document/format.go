@@ -0,0 +1,46 @@
+package document
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// FormatAmount renders an amount in the currency's minor unit for display,
+// e.g. 12345 JPY as "¥12,345" and 12345 USD as "$123.45".
+func FormatAmount(amount int64, currency string) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	switch currency {
+	case "JPY":
+		return sign + "¥" + groupThousands(amount)
+	case "USD":
+		return fmt.Sprintf("%s$%s.%02d", sign, groupThousands(amount/100), amount%100)
+	default:
+		return fmt.Sprintf("%s%s %s", sign, groupThousands(amount), currency)
+	}
+}
+
+func groupThousands(n int64) string {
+	s := strconv.FormatInt(n, 10)
+	if len(s) <= 3 {
+		return s
+	}
+
+	var b strings.Builder
+	head := len(s) % 3
+	if head > 0 {
+		b.WriteString(s[:head])
+	}
+	for i := head; i < len(s); i += 3 {
+		if b.Len() > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(s[i : i+3])
+	}
+	return b.String()
+}
@@ -0,0 +1,180 @@
+package document
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strconv"
+	"time"
+
+	"GO-API/internal/domain/model"
+	"GO-API/internal/pkg/pdf"
+)
+
+const (
+	reducedRateMark = "※"
+	dateLayoutJA    = "2006年1月2日"
+)
+
+var templateFuncs = template.FuncMap{
+	"amount": FormatAmount,
+	"date": func(t time.Time) string {
+		return t.Format(dateLayoutJA)
+	},
+	"reduced": func(rate model.TaxRate) bool {
+		return rate == model.TaxRateReduced
+	},
+}
+
+var invoiceTemplate = template.Must(template.New("invoice").Funcs(templateFuncs).Parse(`<!DOCTYPE html>
+<html lang="ja">
+<head>
+<meta charset="utf-8">
+<title>請求書 {{.InvoiceNumber}}</title>
+<style>
+body { font-family: "Hiragino Mincho ProN", "Yu Mincho", serif; margin: 40px; color: #222; }
+h1 { text-align: center; letter-spacing: 0.5em; }
+table { width: 100%; border-collapse: collapse; margin-top: 16px; }
+th, td { border: 1px solid #999; padding: 6px 8px; }
+td.num { text-align: right; }
+.meta { display: flex; justify-content: space-between; }
+.total { font-size: 1.4em; margin-top: 16px; }
+@media print { body { margin: 0; } }
+</style>
+</head>
+<body>
+<h1>請求書</h1>
+<div class="meta">
+  <div>
+    <p><strong>{{.CustomerName}} 御中</strong></p>
+    <p class="total">ご請求金額 {{amount .Total .Currency}}（税込）</p>
+  </div>
+  <div>
+    <p>請求書番号: {{.InvoiceNumber}}</p>
+    <p>発行日: {{date .IssueDate}}</p>
+    {{if .DueDate}}<p>お支払期限: {{date .DueDate}}</p>{{end}}
+    <p>{{.Issuer.Name}}</p>
+    {{if .Issuer.Address}}<p>{{.Issuer.Address}}</p>{{end}}
+    <p>登録番号: {{.Issuer.RegistrationNumber}}</p>
+  </div>
+</div>
+<table>
+  <thead><tr><th>品目</th><th>数量</th><th>単価</th><th>税率</th><th>金額</th></tr></thead>
+  <tbody>
+  {{range .LineItems}}
+    <tr>
+      <td>{{.Description}}{{if reduced .TaxRate}} ※{{end}}</td>
+      <td class="num">{{.Quantity}}</td>
+      <td class="num">{{amount .UnitPrice $.Currency}}</td>
+      <td class="num">{{.TaxRate}}%</td>
+      <td class="num">{{amount .Amount $.Currency}}</td>
+    </tr>
+  {{end}}
+  </tbody>
+</table>
+<table>
+  <thead><tr><th>税率</th><th>対象金額（税抜）</th><th>消費税額</th></tr></thead>
+  <tbody>
+  {{range .TaxSummaries}}
+    <tr>
+      <td>{{.TaxRate}}%対象</td>
+      <td class="num">{{amount .TaxableAmount $.Currency}}</td>
+      <td class="num">{{amount .TaxAmount $.Currency}}</td>
+    </tr>
+  {{end}}
+    <tr><td>小計</td><td class="num">{{amount .Subtotal .Currency}}</td><td class="num">{{amount .TaxTotal .Currency}}</td></tr>
+    <tr><td>合計</td><td class="num" colspan="2">{{amount .Total .Currency}}</td></tr>
+  </tbody>
+</table>
+<p>※は軽減税率（8%）対象品目です。</p>
+{{if eq .Status "paid"}}<p><strong>お支払済み</strong>{{if .PaidAt}}（{{date .PaidAt}}）{{end}}</p>{{end}}
+</body>
+</html>
+`))
+
+func RenderInvoiceHTML(w io.Writer, invoice *model.Invoice) error {
+	return invoiceTemplate.Execute(w, invoice)
+}
+
+func RenderInvoicePDF(w io.Writer, invoice *model.Invoice) error {
+	const (
+		left  = 50.0
+		right = pdf.PageWidth - 50
+	)
+
+	doc := pdf.New()
+	doc.AddPage()
+
+	doc.TextCenter(pdf.PageWidth/2, 70, 22, "請求書")
+
+	doc.Text(left, 120, 13, invoice.CustomerName+" 御中")
+	doc.Line(left, 125, 280, 125, 0.8)
+	doc.Text(left, 160, 15, fmt.Sprintf("ご請求金額 %s（税込）", FormatAmount(invoice.Total, invoice.Currency)))
+
+	y := 110.0
+	doc.TextRight(right, y, 9, "請求書番号: "+invoice.InvoiceNumber)
+	y += 14
+	doc.TextRight(right, y, 9, "発行日: "+invoice.IssueDate.Format(dateLayoutJA))
+	if invoice.DueDate != nil {
+		y += 14
+		doc.TextRight(right, y, 9, "お支払期限: "+invoice.DueDate.Format(dateLayoutJA))
+	}
+	y += 20
+	doc.TextRight(right, y, 10, invoice.Issuer.Name)
+	if invoice.Issuer.Address != "" {
+		y += 14
+		doc.TextRight(right, y, 9, invoice.Issuer.Address)
+	}
+	y += 14
+	doc.TextRight(right, y, 9, "登録番号: "+invoice.Issuer.RegistrationNumber)
+
+	y = 230
+	columns := []float64{left + 4, 320, 400, 440, right - 4}
+	doc.Line(left, y-12, right, y-12, 0.5)
+	doc.Text(columns[0], y, 9, "品目")
+	doc.TextRight(columns[1], y, 9, "数量")
+	doc.TextRight(columns[2], y, 9, "単価")
+	doc.TextRight(columns[3], y, 9, "税率")
+	doc.TextRight(columns[4], y, 9, "金額")
+	doc.Line(left, y+6, right, y+6, 0.5)
+
+	for _, item := range invoice.LineItems {
+		y += 20
+		if y > pdf.PageHeight-160 {
+			doc.AddPage()
+			y = 60
+		}
+		description := item.Description
+		if item.TaxRate == model.TaxRateReduced {
+			description += " " + reducedRateMark
+		}
+		doc.Text(columns[0], y, 9, description)
+		doc.TextRight(columns[1], y, 9, strconv.FormatInt(item.Quantity, 10))
+		doc.TextRight(columns[2], y, 9, FormatAmount(item.UnitPrice, invoice.Currency))
+		doc.TextRight(columns[3], y, 9, fmt.Sprintf("%d%%", item.TaxRate))
+		doc.TextRight(columns[4], y, 9, FormatAmount(item.Amount, invoice.Currency))
+	}
+	doc.Line(left, y+8, right, y+8, 0.5)
+
+	y += 36
+	for _, summary := range invoice.TaxSummaries {
+		doc.Text(300, y, 9, fmt.Sprintf("%d%%対象 %s", summary.TaxRate, FormatAmount(summary.TaxableAmount, invoice.Currency)))
+		doc.TextRight(right-4, y, 9, "消費税 "+FormatAmount(summary.TaxAmount, invoice.Currency))
+		y += 16
+	}
+	doc.Text(300, y, 9, "小計 "+FormatAmount(invoice.Subtotal, invoice.Currency))
+	doc.TextRight(right-4, y, 9, "消費税合計 "+FormatAmount(invoice.TaxTotal, invoice.Currency))
+	y += 20
+	doc.Line(300, y-12, right, y-12, 0.5)
+	doc.TextRight(right-4, y, 12, "合計 "+FormatAmount(invoice.Total, invoice.Currency))
+
+	y += 30
+	doc.Text(left, y, 8, reducedRateMark+"は軽減税率（8%）対象品目です。")
+	if invoice.Status == model.InvoiceStatusPaid {
+		y += 16
+		doc.Text(left, y, 10, "お支払済み")
+	}
+
+	_, err := doc.WriteTo(w)
+	return err
+}
@@ -0,0 +1,158 @@
+package document
+
+import (
+	"html/template"
+	"io"
+
+	"GO-API/internal/domain/model"
+	"GO-API/internal/pkg/pdf"
+)
+
+const (
+	LanguageJapanese = "ja"
+	LanguageEnglish  = "en"
+
+	dateLayoutEN = "January 2, 2006"
+)
+
+type receiptLabels struct {
+	Lang          string
+	Title         string
+	Reissue       string
+	Honorific     string
+	AmountSuffix  string
+	ProvisoPrefix string
+	Acknowledge   string
+	Number        string
+	IssueDate     string
+	PaidDate      string
+	Transaction   string
+	Registration  string
+	DateLayout    string
+}
+
+var receiptLabelsByLang = map[string]receiptLabels{
+	LanguageJapanese: {
+		Lang:          LanguageJapanese,
+		Title:         "領収書",
+		Reissue:       "再発行",
+		Honorific:     " 様",
+		AmountSuffix:  "-",
+		ProvisoPrefix: "但し ",
+		Acknowledge:   "上記正に領収いたしました",
+		Number:        "領収書番号",
+		IssueDate:     "発行日",
+		PaidDate:      "お支払日",
+		Transaction:   "取引ID",
+		Registration:  "登録番号",
+		DateLayout:    dateLayoutJA,
+	},
+	LanguageEnglish: {
+		Lang:          LanguageEnglish,
+		Title:         "RECEIPT",
+		Reissue:       "REISSUED",
+		Honorific:     "",
+		AmountSuffix:  "",
+		ProvisoPrefix: "For: ",
+		Acknowledge:   "Received with thanks the amount stated above.",
+		Number:        "Receipt No.",
+		IssueDate:     "Issue date",
+		PaidDate:      "Payment date",
+		Transaction:   "Transaction ID",
+		Registration:  "Registration No.",
+		DateLayout:    dateLayoutEN,
+	},
+}
+
+func SupportedLanguage(lang string) bool {
+	_, ok := receiptLabelsByLang[lang]
+	return ok
+}
+
+type receiptView struct {
+	*model.Receipt
+	L receiptLabels
+}
+
+var receiptTemplate = template.Must(template.New("receipt").Funcs(templateFuncs).Parse(`<!DOCTYPE html>
+<html lang="{{.L.Lang}}">
+<head>
+<meta charset="utf-8">
+<title>{{.L.Title}} {{.ReceiptNumber}}</title>
+<style>
+body { font-family: "Hiragino Mincho ProN", "Yu Mincho", serif; margin: 40px; color: #222; }
+h1 { text-align: center; letter-spacing: 0.3em; }
+.reissue { color: #c00; border: 2px solid #c00; padding: 2px 8px; float: right; }
+.recipient { font-size: 1.3em; border-bottom: 1px solid #222; display: inline-block; min-width: 50%; }
+.amount { font-size: 2em; text-align: center; border: 1px solid #222; padding: 12px; margin: 24px 0; }
+.issuer { text-align: right; margin-top: 32px; }
+@media print { body { margin: 0; } }
+</style>
+</head>
+<body>
+{{if .Reissued}}<div class="reissue">{{.L.Reissue}}</div>{{end}}
+<h1>{{.L.Title}}</h1>
+<p>{{.L.Number}}: {{.ReceiptNumber}}<br>{{.L.IssueDate}}: {{.IssuedAt.Format .L.DateLayout}}</p>
+<p class="recipient">{{.RecipientName}}{{.L.Honorific}}</p>
+<div class="amount">{{amount .Amount .Currency}}{{.L.AmountSuffix}}</div>
+<p>{{.L.ProvisoPrefix}}{{.Proviso}}</p>
+<p>{{.L.Acknowledge}}</p>
+<p>{{.L.PaidDate}}: {{.PaidAt.Format .L.DateLayout}}<br>{{.L.Transaction}}: {{.TransactionID}}</p>
+<div class="issuer">
+  <p>{{.Issuer.Name}}</p>
+  {{if .Issuer.Address}}<p>{{.Issuer.Address}}</p>{{end}}
+  {{if .Issuer.RegistrationNumber}}<p>{{.L.Registration}}: {{.Issuer.RegistrationNumber}}</p>{{end}}
+</div>
+</body>
+</html>
+`))
+
+func RenderReceiptHTML(w io.Writer, receipt *model.Receipt, lang string) error {
+	return receiptTemplate.Execute(w, receiptView{Receipt: receipt, L: receiptLabelsByLang[lang]})
+}
+
+func RenderReceiptPDF(w io.Writer, receipt *model.Receipt, lang string) error {
+	const (
+		left  = 60.0
+		right = pdf.PageWidth - 60
+	)
+	l := receiptLabelsByLang[lang]
+
+	doc := pdf.New()
+	doc.AddPage()
+
+	if receipt.Reissued() {
+		doc.Rect(right-70, 40, 70, 22, 1.2)
+		doc.TextCenter(right-35, 56, 11, l.Reissue)
+	}
+	doc.TextCenter(pdf.PageWidth/2, 90, 24, l.Title)
+
+	doc.TextRight(right, 130, 9, l.Number+": "+receipt.ReceiptNumber)
+	doc.TextRight(right, 144, 9, l.IssueDate+": "+receipt.IssuedAt.Format(l.DateLayout))
+
+	doc.Text(left, 190, 15, receipt.RecipientName+l.Honorific)
+	doc.Line(left, 197, 340, 197, 0.8)
+
+	doc.Rect(left, 225, right-left, 56, 1)
+	doc.TextCenter(pdf.PageWidth/2, 263, 24, FormatAmount(receipt.Amount, receipt.Currency)+l.AmountSuffix)
+
+	doc.Text(left, 315, 11, l.ProvisoPrefix+receipt.Proviso)
+	doc.Text(left, 337, 11, l.Acknowledge)
+
+	doc.Text(left, 375, 9, l.PaidDate+": "+receipt.PaidAt.Format(l.DateLayout))
+	doc.Text(left, 389, 9, l.Transaction+": "+receipt.TransactionID)
+
+	y := 440.0
+	doc.TextRight(right, y, 11, receipt.Issuer.Name)
+	if receipt.Issuer.Address != "" {
+		y += 16
+		doc.TextRight(right, y, 9, receipt.Issuer.Address)
+	}
+	if receipt.Issuer.RegistrationNumber != "" {
+		y += 14
+		doc.TextRight(right, y, 9, l.Registration+": "+receipt.Issuer.RegistrationNumber)
+	}
+
+	_, err := doc.WriteTo(w)
+	return err
+}
@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"GO-API/internal/domain/model"
+	"GO-API/internal/pkg/auth"
+	"GO-API/internal/pkg/logger"
+	"GO-API/internal/usecase"
+)
+
+type APIKeyHandler struct {
+	apiKeyUseCase *usecase.APIKeyUseCase
+}
+
+func NewAPIKeyHandler(ku *usecase.APIKeyUseCase) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyUseCase: ku,
+	}
+}
+
+type CreateAPIKeyRequest struct {
+	Name       string   `json:"name"`
+	Livemode   bool     `json:"livemode"`
+	Scopes     []string `json:"scopes"`
+	AllowedIPs []string `json:"allowed_ips"`
+}
+
+type RotateAPIKeyRequest struct {
+	GracePeriodSeconds int64 `json:"grace_period_seconds"`
+}
+
+// APIKeyWithSecret is returned only when a key is created or rotated; the
+// secret cannot be retrieved afterwards.
+type APIKeyWithSecret struct {
+	*model.APIKey
+	Secret string `json:"secret" log:"drop"`
+}
+
+func (h *APIKeyHandler) RegisterRoutes(r *mux.Router) {
+	r.HandleFunc("/api/v1/api-keys", requirePermission(auth.PermissionAdmin, h.CreateKey)).Methods(http.MethodPost)
+	r.HandleFunc("/api/v1/api-keys", requirePermission(auth.PermissionAdmin, h.ListKeys)).Methods(http.MethodGet)
+	r.HandleFunc("/api/v1/api-keys/{id}", requirePermission(auth.PermissionAdmin, h.GetKey)).Methods(http.MethodGet)
+	r.HandleFunc("/api/v1/api-keys/{id}/rotate", requirePermission(auth.PermissionAdmin, h.RotateKey)).Methods(http.MethodPost)
+	r.HandleFunc("/api/v1/api-keys/{id}/revoke", requirePermission(auth.PermissionAdmin, h.RevokeKey)).Methods(http.MethodPost)
+}
+
+func (h *APIKeyHandler) CreateKey(w http.ResponseWriter, r *http.Request) {
+	logger.InfoContext(r.Context(), "Received create API key request")
+
+	var req CreateAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.ErrorContext(r.Context(), "Failed to decode request body", "error", err)
+		writeError(w, r, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	key, secret, err := h.apiKeyUseCase.CreateKey(r.Context(), usecase.CreateAPIKeyInput{
+		Name:       req.Name,
+		Livemode:   req.Livemode,
+		Scopes:     req.Scopes,
+		AllowedIPs: req.AllowedIPs,
+	})
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Failed to create API key", "error", err)
+		handleError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, r, http.StatusCreated, APIKeyWithSecret{APIKey: key, Secret: secret})
+}
+
+func (h *APIKeyHandler) GetKey(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	key, err := h.apiKeyUseCase.GetKey(r.Context(), id)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Error getting API key", "error", err)
+		handleError(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, key)
+}
+
+func (h *APIKeyHandler) ListKeys(w http.ResponseWriter, r *http.Request) {
+	limit, offset := parsePagination(r)
+
+	keys, err := h.apiKeyUseCase.ListKeys(r.Context(), limit, offset)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Failed to fetch API keys", "error", err)
+		handleError(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, keys)
+}
+
+func (h *APIKeyHandler) RotateKey(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	var req RotateAPIKeyRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			logger.ErrorContext(r.Context(), "Failed to decode request body", "error", err)
+			writeError(w, r, http.StatusBadRequest, "invalid request body")
+			return
+		}
+	}
+
+	key, secret, err := h.apiKeyUseCase.RotateKey(r.Context(), id, time.Duration(req.GracePeriodSeconds)*time.Second)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Failed to rotate API key", "error", err)
+		handleError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, r, http.StatusCreated, APIKeyWithSecret{APIKey: key, Secret: secret})
+}
+
+func (h *APIKeyHandler) RevokeKey(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	key, err := h.apiKeyUseCase.RevokeKey(r.Context(), id)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Failed to revoke API key", "error", err)
+		handleError(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, key)
+}
@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"GO-API/internal/pkg/auth"
+	"GO-API/internal/pkg/logger"
+	"GO-API/internal/usecase"
+)
+
+type AuditHandler struct {
+	auditUseCase *usecase.AuditUseCase
+}
+
+func NewAuditHandler(au *usecase.AuditUseCase) *AuditHandler {
+	return &AuditHandler{
+		auditUseCase: au,
+	}
+}
+
+func (h *AuditHandler) RegisterRoutes(r *mux.Router) {
+	r.HandleFunc("/api/v1/audit-entries", requirePermission(auth.PermissionAdmin, h.ListEntries)).Methods(http.MethodGet)
+}
+
+func (h *AuditHandler) ListEntries(w http.ResponseWriter, r *http.Request) {
+	limit, offset := parsePagination(r)
+
+	entries, err := h.auditUseCase.ListEntries(r.Context(), limit, offset)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Failed to fetch audit entries", "error", err)
+		handleError(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, entries)
+}
@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"GO-API/internal/domain/model"
+	"GO-API/internal/pkg/auth"
+	"GO-API/internal/pkg/logger"
+	"GO-API/internal/usecase"
+)
+
+const (
+	GrantTypeClientCredentials = "client_credentials"
+	GrantTypeRefreshToken      = "refresh_token"
+)
+
+// Paths of the endpoints that authenticate with client credentials instead of
+// a bearer token.
+const (
+	TokenPath  = "/api/v1/auth/token"
+	RevokePath = "/api/v1/auth/revoke"
+)
+
+type AuthHandler struct {
+	authUseCase *usecase.AuthUseCase
+}
+
+func NewAuthHandler(au *usecase.AuthUseCase) *AuthHandler {
+	return &AuthHandler{
+		authUseCase: au,
+	}
+}
+
+type CreateAPIClientRequest struct {
+	Name     string `json:"name"`
+	Role     string `json:"role"`
+	Livemode bool   `json:"livemode"`
+}
+
+type CreateAPIClientResponse struct {
+	*model.APIClient
+	ClientSecret string `json:"client_secret" log:"drop"`
+}
+
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+}
+
+func (h *AuthHandler) RegisterRoutes(r *mux.Router) {
+	r.HandleFunc(TokenPath, h.Token).Methods(http.MethodPost)
+	r.HandleFunc(RevokePath, h.Revoke).Methods(http.MethodPost)
+	r.HandleFunc("/api/v1/auth/clients", requirePermission(auth.PermissionAdmin, h.CreateClient)).Methods(http.MethodPost)
+}
+
+func (h *AuthHandler) CreateClient(w http.ResponseWriter, r *http.Request) {
+	logger.InfoContext(r.Context(), "Received create API client request")
+
+	var req CreateAPIClientRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.ErrorContext(r.Context(), "Failed to decode request body", "error", err)
+		writeError(w, r, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	client, secret, err := h.authUseCase.CreateClient(r.Context(), usecase.CreateAPIClientInput{
+		Name:     req.Name,
+		Role:     req.Role,
+		Livemode: req.Livemode,
+	})
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Failed to create API client", "error", err)
+		handleError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, r, http.StatusCreated, CreateAPIClientResponse{
+		APIClient:    client,
+		ClientSecret: secret,
+	})
+}
+
+// Token is the OAuth 2.0 token endpoint. It accepts form-encoded
+// client_credentials and refresh_token grants, with the client authenticated
+// by HTTP Basic or client_id/client_secret form fields.
+func (h *AuthHandler) Token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeError(w, r, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	clientID, clientSecret := clientCredentials(r)
+
+	var tokens *usecase.IssuedTokens
+	var err error
+	switch grantType := r.PostForm.Get("grant_type"); grantType {
+	case GrantTypeClientCredentials:
+		tokens, err = h.authUseCase.ClientCredentials(r.Context(), clientID, clientSecret)
+	case GrantTypeRefreshToken:
+		refreshToken := r.PostForm.Get("refresh_token")
+		if refreshToken == "" {
+			writeError(w, r, http.StatusBadRequest, "refresh_token is required")
+			return
+		}
+		tokens, err = h.authUseCase.Refresh(r.Context(), clientID, clientSecret, refreshToken)
+	default:
+		logger.InfoContext(r.Context(), "Unsupported grant type", "grant_type", grantType)
+		writeError(w, r, http.StatusBadRequest, "unsupported grant_type")
+		return
+	}
+
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Failed to issue token", "error", err)
+		handleError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, r, http.StatusOK, TokenResponse{
+		AccessToken:  tokens.AccessToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(tokens.ExpiresIn.Seconds()),
+		RefreshToken: tokens.RefreshToken,
+	})
+}
+
+// Revoke is the RFC 7009 revocation endpoint. It answers 200 for any token
+// the client is allowed to present, whether or not it was still valid.
+func (h *AuthHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeError(w, r, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	clientID, clientSecret := clientCredentials(r)
+
+	err := h.authUseCase.Revoke(r.Context(), clientID, clientSecret,
+		r.PostForm.Get("token"), r.PostForm.Get("token_type_hint"))
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Failed to revoke token", "error", err)
+		handleError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func clientCredentials(r *http.Request) (string, string) {
+	if id, secret, ok := r.BasicAuth(); ok {
+		return id, secret
+	}
+	return r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+}
@@ -0,0 +1,26 @@
+package handler
+
+import (
+	"net/http"
+
+	"GO-API/internal/pkg/auth"
+	"GO-API/internal/pkg/logger"
+)
+
+func requirePermission(permission auth.Permission, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal, ok := auth.PrincipalFromContext(r.Context())
+		if !ok {
+			writeError(w, r, http.StatusUnauthorized, "authentication required")
+			return
+		}
+
+		if !principal.HasPermission(permission) {
+			logger.InfoContext(r.Context(), "Permission denied", "permission", permission, "subject", principal.Subject, "grants", principal.Grants())
+			writeError(w, r, http.StatusForbidden, "insufficient permissions")
+			return
+		}
+
+		next(w, r)
+	}
+}
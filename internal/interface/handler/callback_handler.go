@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"GO-API/internal/pkg/logger"
+	"GO-API/internal/usecase"
+)
+
+// CallbackPathPrefix is where processors deliver callbacks. They authenticate
+// with a request signature instead of a bearer token.
+const CallbackPathPrefix = "/api/v1/callbacks/"
+
+type CallbackHandler struct {
+	callbackUseCase *usecase.CallbackUseCase
+}
+
+func NewCallbackHandler(cu *usecase.CallbackUseCase) *CallbackHandler {
+	return &CallbackHandler{
+		callbackUseCase: cu,
+	}
+}
+
+// RegisterRoutes registers the callback route behind verify, which must
+// authenticate the request signature.
+func (h *CallbackHandler) RegisterRoutes(r *mux.Router, verify func(http.Handler) http.Handler) {
+	r.Handle(CallbackPathPrefix+"{provider}", verify(http.HandlerFunc(h.HandleCallback))).Methods(http.MethodPost)
+}
+
+func (h *CallbackHandler) HandleCallback(w http.ResponseWriter, r *http.Request) {
+	provider := mux.Vars(r)["provider"]
+	logger.InfoContext(r.Context(), "Received callback", "provider", provider)
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if _, err := h.callbackUseCase.HandleCallback(r.Context(), provider, body); err != nil {
+		logger.ErrorContext(r.Context(), "Failed to handle callback", "error", err)
+		handleError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
@@ -5,6 +5,9 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/gorilla/mux"
+
+	"GO-API/internal/pkg/health"
 	"GO-API/internal/pkg/logger"
 )
 
@@ -13,8 +16,10 @@ type HealthResponse struct {
 	Timestamp string `json:"time"`
 }
 
+// HealthCheck is the original health endpoint. It only reports that the
+// process is serving requests; use /health/ready for dependency checks.
 func HealthCheck(w http.ResponseWriter, r *http.Request) {
-	logger.Info("Health check requested")
+	logger.InfoContext(r.Context(), "Health check requested")
 
 	response := HealthResponse{
 		Status:    "ok",
@@ -24,5 +29,51 @@ func HealthCheck(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 
-	logger.Debug("Health check responded successfully")
+	logger.DebugContext(r.Context(), "Health check responded successfully")
+}
+
+type HealthHandler struct {
+	registry *health.Registry
+}
+
+func NewHealthHandler(registry *health.Registry) *HealthHandler {
+	return &HealthHandler{
+		registry: registry,
+	}
+}
+
+type ReadinessResponse struct {
+	health.Report
+	Timestamp string `json:"time"`
+}
+
+func (h *HealthHandler) RegisterRoutes(r *mux.Router) {
+	r.HandleFunc("/health/live", h.Live).Methods(http.MethodGet)
+	r.HandleFunc("/health/ready", h.Ready).Methods(http.MethodGet)
+}
+
+// Live answers as long as the process can serve requests. It deliberately
+// checks no dependencies, so an outage of Postgres does not get every
+// replica restarted.
+func (h *HealthHandler) Live(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, r, http.StatusOK, HealthResponse{
+		Status:    health.StatusUp,
+		Timestamp: time.Now().Format(time.RFC3339),
+	})
+}
+
+// Ready runs the registered dependency checks and answers 503 if any fails
+// or the server is shutting down.
+func (h *HealthHandler) Ready(w http.ResponseWriter, r *http.Request) {
+	report := h.registry.Run(r.Context())
+
+	status := http.StatusOK
+	if !report.Up() {
+		status = http.StatusServiceUnavailable
+		logger.WarnContext(r.Context(), "Readiness check failed", "checks", report.Checks)
+	}
+	writeJSON(w, r, status, ReadinessResponse{
+		Report:    report,
+		Timestamp: time.Now().Format(time.RFC3339),
+	})
 }
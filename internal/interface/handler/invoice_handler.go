@@ -0,0 +1,172 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"GO-API/internal/interface/document"
+	"GO-API/internal/pkg/auth"
+	"GO-API/internal/pkg/logger"
+	"GO-API/internal/usecase"
+)
+
+type InvoiceHandler struct {
+	invoiceUseCase *usecase.InvoiceUseCase
+}
+
+func NewInvoiceHandler(iu *usecase.InvoiceUseCase) *InvoiceHandler {
+	return &InvoiceHandler{
+		invoiceUseCase: iu,
+	}
+}
+
+type InvoiceLineItemRequest struct {
+	Description string `json:"description"`
+	Quantity    int64  `json:"quantity"`
+	UnitPrice   int64  `json:"unit_price"`
+	TaxRate     int    `json:"tax_rate"`
+}
+
+type CreateInvoiceRequest struct {
+	CustomerID   string                   `json:"customer_id" log:"hash"`
+	CustomerName string                   `json:"customer_name" log:"mask"`
+	Currency     string                   `json:"currency"`
+	TaxInclusive bool                     `json:"tax_inclusive"`
+	TaxRounding  string                   `json:"tax_rounding"`
+	LineItems    []InvoiceLineItemRequest `json:"line_items"`
+	DueDate      *time.Time               `json:"due_date"`
+}
+
+type PayInvoiceRequest struct {
+	PaymentMethod string `json:"payment_method"`
+}
+
+func (h *InvoiceHandler) RegisterRoutes(r *mux.Router) {
+	r.HandleFunc("/api/v1/invoices", requirePermission(auth.PermissionPaymentsWrite, h.CreateInvoice)).Methods(http.MethodPost)
+	r.HandleFunc("/api/v1/invoices/{id}", requirePermission(auth.PermissionPaymentsRead, h.GetInvoice)).Methods(http.MethodGet)
+	r.HandleFunc("/api/v1/invoices", requirePermission(auth.PermissionPaymentsRead, h.ListInvoices)).Methods(http.MethodGet)
+	r.HandleFunc("/api/v1/invoices/{id}/pay", requirePermission(auth.PermissionPaymentsWrite, h.PayInvoice)).Methods(http.MethodPost)
+	r.HandleFunc("/api/v1/invoices/{id}/print", requirePermission(auth.PermissionPaymentsRead, h.PrintInvoice)).Methods(http.MethodGet)
+}
+
+func (h *InvoiceHandler) CreateInvoice(w http.ResponseWriter, r *http.Request) {
+	logger.InfoContext(r.Context(), "Received create invoice request")
+
+	var req CreateInvoiceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.ErrorContext(r.Context(), "Failed to decode request body", "error", err)
+		writeError(w, r, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	items := make([]usecase.InvoiceLineItemInput, len(req.LineItems))
+	for i, item := range req.LineItems {
+		items[i] = usecase.InvoiceLineItemInput{
+			Description: item.Description,
+			Quantity:    item.Quantity,
+			UnitPrice:   item.UnitPrice,
+			TaxRate:     item.TaxRate,
+		}
+	}
+
+	invoice, err := h.invoiceUseCase.CreateInvoice(r.Context(), usecase.CreateInvoiceInput{
+		CustomerID:   req.CustomerID,
+		CustomerName: req.CustomerName,
+		Currency:     req.Currency,
+		TaxInclusive: req.TaxInclusive,
+		TaxRounding:  req.TaxRounding,
+		LineItems:    items,
+		DueDate:      req.DueDate,
+	})
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Failed to create invoice", "error", err)
+		handleError(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, http.StatusCreated, invoice)
+}
+
+func (h *InvoiceHandler) GetInvoice(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	invoice, err := h.invoiceUseCase.GetInvoice(r.Context(), id)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Error getting invoice", "error", err)
+		handleError(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, invoice)
+}
+
+func (h *InvoiceHandler) ListInvoices(w http.ResponseWriter, r *http.Request) {
+	limit, offset := parsePagination(r)
+
+	invoices, err := h.invoiceUseCase.ListInvoices(r.Context(), limit, offset)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Failed to fetch invoices", "error", err)
+		handleError(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, invoices)
+}
+
+func (h *InvoiceHandler) PayInvoice(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	var req PayInvoiceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.ErrorContext(r.Context(), "Failed to decode request body", "error", err)
+		writeError(w, r, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	invoice, err := h.invoiceUseCase.PayInvoice(r.Context(), id, req.PaymentMethod)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Failed to pay invoice", "error", err)
+		handleError(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, invoice)
+}
+
+func (h *InvoiceHandler) PrintInvoice(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	invoice, err := h.invoiceUseCase.GetInvoice(r.Context(), id)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Error getting invoice", "error", err)
+		handleError(w, r, err)
+		return
+	}
+
+	var buf bytes.Buffer
+	var contentType, filename string
+	switch format := r.URL.Query().Get("format"); format {
+	case "", "html":
+		contentType = "text/html; charset=utf-8"
+		err = document.RenderInvoiceHTML(&buf, invoice)
+	case "pdf":
+		contentType = "application/pdf"
+		filename = invoice.InvoiceNumber + ".pdf"
+		err = document.RenderInvoicePDF(&buf, invoice)
+	default:
+		writeError(w, r, http.StatusBadRequest, "unsupported format")
+		return
+	}
+
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Failed to render invoice", "error", err)
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	writeDocument(w, r, contentType, filename, buf.Bytes())
+}
@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"GO-API/internal/pkg/logger"
+)
+
+type LogLevelRequest struct {
+	Level string `json:"level"`
+}
+
+type LogLevelResponse struct {
+	Level string `json:"level"`
+}
+
+// RegisterLogLevelRoutes exposes the log level of this process so that it can
+// be raised while investigating an incident without a restart. The level is
+// process wide, not merchant scoped, so r must be the internal admin listener
+// reachable only by platform operators, never the public API router.
+func RegisterLogLevelRoutes(r *mux.Router) {
+	r.HandleFunc("/api/v1/log-level", GetLogLevel).Methods(http.MethodGet)
+	r.HandleFunc("/api/v1/log-level", SetLogLevel).Methods(http.MethodPut)
+}
+
+func GetLogLevel(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, r, http.StatusOK, LogLevelResponse{Level: logger.Level().String()})
+}
+
+func SetLogLevel(w http.ResponseWriter, r *http.Request) {
+	var req LogLevelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.ErrorContext(r.Context(), "Failed to decode request body", "error", err)
+		writeError(w, r, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	level, err := logger.ParseLevel(req.Level)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "level must be one of debug, info, warn, error")
+		return
+	}
+
+	logger.SetLevel(level)
+	logger.WarnContext(r.Context(), "Log level changed", "level", level.String())
+	writeJSON(w, r, http.StatusOK, LogLevelResponse{Level: level.String()})
+}
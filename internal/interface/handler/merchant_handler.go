@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"GO-API/internal/domain/model"
+	"GO-API/internal/pkg/auth"
+	"GO-API/internal/pkg/logger"
+	"GO-API/internal/usecase"
+)
+
+type MerchantHandler struct {
+	merchantUseCase *usecase.MerchantUseCase
+}
+
+func NewMerchantHandler(mu *usecase.MerchantUseCase) *MerchantHandler {
+	return &MerchantHandler{
+		merchantUseCase: mu,
+	}
+}
+
+type UpdateMerchantRequest struct {
+	Name                string   `json:"name"`
+	AllowedCurrencies   []string `json:"allowed_currencies"`
+	PaymentMethods      []string `json:"payment_methods"`
+	TransactionIDPrefix string   `json:"transaction_id_prefix"`
+	MinAmount           int64    `json:"min_amount"`
+	MaxAmount           int64    `json:"max_amount"`
+	// InvoiceIssuer is printed on qualified invoices and receipts. Without one
+	// the merchant cannot create invoices, except the default merchant, which
+	// falls back to the configured issuer.
+	InvoiceIssuer model.InvoiceIssuer `json:"invoice_issuer"`
+}
+
+func (h *MerchantHandler) RegisterRoutes(r *mux.Router) {
+	r.HandleFunc("/api/v1/merchant", requirePermission(auth.PermissionPaymentsRead, h.GetMerchant)).Methods(http.MethodGet)
+	r.HandleFunc("/api/v1/merchant", requirePermission(auth.PermissionAdmin, h.UpdateMerchant)).Methods(http.MethodPut)
+}
+
+func (h *MerchantHandler) GetMerchant(w http.ResponseWriter, r *http.Request) {
+	merchant, err := h.merchantUseCase.GetMerchant(r.Context())
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Error getting merchant", "error", err)
+		handleError(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, merchant)
+}
+
+func (h *MerchantHandler) UpdateMerchant(w http.ResponseWriter, r *http.Request) {
+	logger.InfoContext(r.Context(), "Received update merchant request")
+
+	var req UpdateMerchantRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.ErrorContext(r.Context(), "Failed to decode request body", "error", err)
+		writeError(w, r, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	merchant, err := h.merchantUseCase.UpdateMerchant(r.Context(), usecase.UpdateMerchantInput{
+		Name:                req.Name,
+		AllowedCurrencies:   req.AllowedCurrencies,
+		PaymentMethods:      req.PaymentMethods,
+		TransactionIDPrefix: req.TransactionIDPrefix,
+		MinAmount:           req.MinAmount,
+		MaxAmount:           req.MaxAmount,
+		InvoiceIssuer:       req.InvoiceIssuer,
+	})
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Failed to update merchant", "error", err)
+		handleError(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, merchant)
+}
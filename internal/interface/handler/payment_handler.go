@@ -4,9 +4,12 @@ import (
 	"encoding/json"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gorilla/mux"
 
+	"GO-API/internal/domain/model"
+	"GO-API/internal/pkg/auth"
 	"GO-API/internal/pkg/logger"
 	"GO-API/internal/usecase"
 )
@@ -22,29 +25,43 @@ func NewPaymentHandler(pu *usecase.PaymentUseCase) *PaymentHandler {
 }
 
 type CreatePaymentRequest struct {
-	Amount        int64  `json:"amount"`
-	Currency      string `json:"currency"`
-	Description   string `json:"description"`
-	CustomerID    string `json:"customer_id"`
-	PaymentMethod string `jsong:"payment_method"`
-	OrderID       string `json:"order_id"`
+	Amount        int64               `json:"amount"`
+	Currency      string              `json:"currency"`
+	Description   string              `json:"description" log:"drop"`
+	CustomerID    string              `json:"customer_id" log:"hash"`
+	PaymentMethod string              `json:"payment_method"`
+	OrderID       string              `json:"order_id"`
+	Installment   *InstallmentRequest `json:"installment"`
+	ScheduledAt   *time.Time          `json:"scheduled_at"`
+}
+
+type ReschedulePaymentRequest struct {
+	ScheduledAt time.Time `json:"scheduled_at"`
+}
+
+type InstallmentRequest struct {
+	Type  string `json:"type"`
+	Count int    `json:"count"`
 }
 
 func (h *PaymentHandler) RegisterRoutes(r *mux.Router) {
-	r.HandleFunc("/api/v1/payments", h.CreatePayment).Methods(http.MethodPost)
-	r.HandleFunc("/api/v1/payments/{id}", h.GetPayment).Methods(http.MethodGet)
-	r.HandleFunc("/api/v1/payments", h.ListPayments).Methods(http.MethodGet)
+	r.HandleFunc("/api/v1/payments", requirePermission(auth.PermissionPaymentsWrite, h.CreatePayment)).Methods(http.MethodPost)
+	r.HandleFunc("/api/v1/payments/{id}", requirePermission(auth.PermissionPaymentsRead, h.GetPayment)).Methods(http.MethodGet)
+	r.HandleFunc("/api/v1/payments", requirePermission(auth.PermissionPaymentsRead, h.ListPayments)).Methods(http.MethodGet)
+	r.HandleFunc("/api/v1/payments/{id}/reschedule", requirePermission(auth.PermissionPaymentsWrite, h.ReschedulePayment)).Methods(http.MethodPost)
+	r.HandleFunc("/api/v1/payments/{id}/cancel", requirePermission(auth.PermissionPaymentsWrite, h.CancelPayment)).Methods(http.MethodPost)
 }
 
 func (h *PaymentHandler) CreatePayment(w http.ResponseWriter, r *http.Request) {
-	logger.Info("Received create payment request")
+	logger.InfoContext(r.Context(), "Received create payment request")
 
 	var req CreatePaymentRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		logger.Error("Failed to decode request body: %v", err)
-		writeError(w, http.StatusBadRequest, "invalid request body")
+		logger.ErrorContext(r.Context(), "Failed to decode request body", "error", err)
+		writeError(w, r, http.StatusBadRequest, "invalid request body")
 		return
 	}
+	logger.DebugContext(r.Context(), "Decoded create payment request", "request", req)
 
 	input := usecase.CreatePaymentInput{
 		Amount:        req.Amount,
@@ -53,39 +70,46 @@ func (h *PaymentHandler) CreatePayment(w http.ResponseWriter, r *http.Request) {
 		CustomerID:    req.CustomerID,
 		PaymentMethod: req.PaymentMethod,
 		OrderID:       req.OrderID,
+		ScheduledAt:   req.ScheduledAt,
+	}
+	if req.Installment != nil {
+		input.Installment = &model.Installment{
+			Type:  model.InstallmentType(req.Installment.Type),
+			Count: req.Installment.Count,
+		}
 	}
 
 	payment, err := h.paymentUseCase.CreatePayment(r.Context(), input)
 	if err != nil {
-		logger.Error("Failed to create payment: %v", err)
-		handleError(w, err)
+		logger.ErrorContext(r.Context(), "Failed to create payment", "error", err)
+		handleError(w, r, err)
 		return
 	}
 
-	logger.Info("Successfully created payment: ID=%s", payment.ID)
-	writeJSON(w, http.StatusCreated, payment)
+	logger.InfoContext(r.Context(), "Successfully created payment", "payment_id", payment.ID)
+	writeJSON(w, r, http.StatusCreated, payment)
 }
 
 func (h *PaymentHandler) GetPayment(w http.ResponseWriter, r *http.Request) {
-	logger.Info("GetPayment handler called")
+	logger.InfoContext(r.Context(), "GetPayment handler called")
 
 	vars := mux.Vars(r)
 	id := vars["id"]
-	logger.Info("GetPayment handler called")
+	logger.InfoContext(r.Context(), "GetPayment handler called")
 
 	payment, err := h.paymentUseCase.GetPayment(r.Context(), id)
 	if err != nil {
-		logger.Error("Error getting payment; %v", err)
-		handleError(w, err)
+		logger.ErrorContext(r.Context(), "Error getting payment", "error", err)
+		handleError(w, r, err)
 		return
 	}
 
-	logger.Info("Successfully retrieved payment: %+v", payment)
-	writeJSON(w, http.StatusOK, payment)
+	logger.InfoContext(r.Context(), "Successfully retrieved payment", "payment", payment)
+	writeJSON(w, r, http.StatusOK, payment)
 }
 
 func (h *PaymentHandler) ListPayments(w http.ResponseWriter, r *http.Request) {
-	logger.Info("ListPayments handler called")
+	logger.InfoContext(r.Context(), "ListPayments handler called")
 
 	limit := 10
 	offset := 0
@@ -102,15 +126,50 @@ func (h *PaymentHandler) ListPayments(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	logger.Info("Fetching payments with limit=%d, offset=%d", limit, offset)
+	logger.InfoContext(r.Context(), "Fetching payments", "limit", limit, "offset", offset)
 
 	payments, err := h.paymentUseCase.ListPayments(r.Context(), limit, offset)
 	if err != nil {
-		logger.Error("Failed to fetch payments: %v", err)
-		handleError(w, err)
+		logger.ErrorContext(r.Context(), "Failed to fetch payments", "error", err)
+		handleError(w, r, err)
+		return
+	}
+
+	logger.InfoContext(r.Context(), "Successfully fetched payments", "count", len(payments))
+	writeJSON(w, r, http.StatusOK, payments)
+}
+
+func (h *PaymentHandler) ReschedulePayment(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	logger.InfoContext(r.Context(), "ReschedulePayment handler called")
+
+	var req ReschedulePaymentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.ErrorContext(r.Context(), "Failed to decode request body", "error", err)
+		writeError(w, r, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	payment, err := h.paymentUseCase.ReschedulePayment(r.Context(), id, req.ScheduledAt)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Failed to reschedule payment", "error", err)
+		handleError(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, payment)
+}
+
+func (h *PaymentHandler) CancelPayment(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	logger.InfoContext(r.Context(), "CancelPayment handler called")
+
+	payment, err := h.paymentUseCase.CancelScheduledPayment(r.Context(), id)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Failed to cancel payment", "error", err)
+		handleError(w, r, err)
 		return
 	}
 
-	logger.Info("Successfully fetched %d payments", len(payments))
-	writeJSON(w, http.StatusOK, payments)
+	writeJSON(w, r, http.StatusOK, payment)
 }
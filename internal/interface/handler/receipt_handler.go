@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"GO-API/internal/interface/document"
+	"GO-API/internal/pkg/auth"
+	"GO-API/internal/pkg/logger"
+	"GO-API/internal/usecase"
+)
+
+type ReceiptHandler struct {
+	receiptUseCase *usecase.ReceiptUseCase
+}
+
+func NewReceiptHandler(ru *usecase.ReceiptUseCase) *ReceiptHandler {
+	return &ReceiptHandler{
+		receiptUseCase: ru,
+	}
+}
+
+type IssueReceiptRequest struct {
+	RecipientName string `json:"recipient_name" log:"mask"`
+	Proviso       string `json:"proviso"`
+}
+
+type ReissueReceiptRequest struct {
+	Reason string `json:"reason"`
+}
+
+func (h *ReceiptHandler) RegisterRoutes(r *mux.Router) {
+	r.HandleFunc("/api/v1/payments/{id}/receipt", requirePermission(auth.PermissionPaymentsWrite, h.IssueReceipt)).Methods(http.MethodPost)
+	r.HandleFunc("/api/v1/payments/{id}/receipt", requirePermission(auth.PermissionPaymentsRead, h.GetReceipt)).Methods(http.MethodGet)
+	r.HandleFunc("/api/v1/payments/{id}/receipt/reissue", requirePermission(auth.PermissionPaymentsWrite, h.ReissueReceipt)).Methods(http.MethodPost)
+}
+
+func (h *ReceiptHandler) IssueReceipt(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	var req IssueReceiptRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.ErrorContext(r.Context(), "Failed to decode request body", "error", err)
+		writeError(w, r, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	receipt, err := h.receiptUseCase.IssueReceipt(r.Context(), usecase.IssueReceiptInput{
+		PaymentID:     id,
+		RecipientName: req.RecipientName,
+		Proviso:       req.Proviso,
+	})
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Failed to issue receipt", "error", err)
+		handleError(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, http.StatusCreated, receipt)
+}
+
+func (h *ReceiptHandler) ReissueReceipt(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	var req ReissueReceiptRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.ErrorContext(r.Context(), "Failed to decode request body", "error", err)
+		writeError(w, r, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	receipt, err := h.receiptUseCase.ReissueReceipt(r.Context(), usecase.ReissueReceiptInput{
+		PaymentID: id,
+		Reason:    req.Reason,
+	})
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Failed to reissue receipt", "error", err)
+		handleError(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, receipt)
+}
+
+// GetReceipt renders the receipt issued for the payment. It never issues or
+// reissues one.
+
+func (h *ReceiptHandler) GetReceipt(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	query := r.URL.Query()
+
+	lang := query.Get("lang")
+	if lang == "" {
+		lang = document.LanguageJapanese
+	}
+	if !document.SupportedLanguage(lang) {
+		writeError(w, r, http.StatusBadRequest, "unsupported lang")
+		return
+	}
+
+	format := query.Get("format")
+	if format != "" && format != "html" && format != "pdf" {
+		writeError(w, r, http.StatusBadRequest, "unsupported format")
+		return
+	}
+
+	receipt, err := h.receiptUseCase.GetReceipt(r.Context(), id)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Failed to get receipt", "error", err)
+		handleError(w, r, err)
+		return
+	}
+
+	var buf bytes.Buffer
+	var contentType, filename string
+	if format == "pdf" {
+		contentType = "application/pdf"
+		filename = receipt.ReceiptNumber + ".pdf"
+		err = document.RenderReceiptPDF(&buf, receipt, lang)
+	} else {
+		contentType = "text/html; charset=utf-8"
+		err = document.RenderReceiptHTML(&buf, receipt, lang)
+	}
+
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Failed to render receipt", "error", err)
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	writeDocument(w, r, contentType, filename, buf.Bytes())
+}
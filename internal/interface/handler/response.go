@@ -10,42 +10,64 @@ import (
 )
 
 type ErrorResponse struct {
-	Error   string `json:"error"`
-	Message string `json:"message"`
-	Code    string `json:"code"`
+	Error     string `json:"error"`
+	Message   string `json:"message"`
+	Code      string `json:"code"`
+	RequestID string `json:"request_id,omitempty"`
 }
 
-func writeError(w http.ResponseWriter, status int, message string) {
-	logger.Error("Error response: status=%d, message=%s", status, message)
+// RequestIDHeader is set on every response by middleware.RequestID. Error responses repeat
+// the request ID the middleware stored in the request context so it is easy
+// to quote in support requests.
+const RequestIDHeader = "X-Request-ID"
+
+func writeError(w http.ResponseWriter, r *http.Request, status int, message string) {
+	logger.ErrorContext(r.Context(), "Error response", "status", status, "message", message)
 
 	response := ErrorResponse{
-		Error:   http.StatusText(status),
-		Message: message,
-		Code:    fmt.Sprintf("ERR_%d", status),
+		Error:     http.StatusText(status),
+		Message:   message,
+		Code:      fmt.Sprintf("ERR_%d", status),
+		RequestID: logger.RequestID(r.Context()),
 	}
 
-	writeJSON(w, status, response)
+	writeJSON(w, r, status, response)
 }
 
-func writeJSON(w http.ResponseWriter, status int, data interface{}) {
+func writeJSON(w http.ResponseWriter, r *http.Request, status int, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
 	if err := json.NewEncoder(w).Encode(data); err != nil {
-		logger.Error("Failed to encode response: %v", err)
+		logger.ErrorContext(r.Context(), "Failed to encode response", "error", err)
+	}
+}
+
+func writeDocument(w http.ResponseWriter, r *http.Request, contentType string, filename string, body []byte) {
+	w.Header().Set("Content-Type", contentType)
+	if filename != "" {
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`inline; filename="%s"`, filename))
+	}
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(body); err != nil {
+		logger.ErrorContext(r.Context(), "Failed to write document", "error", err)
 	}
 }
 
-func handleError(w http.ResponseWriter, err error) {
+func handleError(w http.ResponseWriter, r *http.Request, err error) {
 	if domainErr, ok := err.(*model.Error); ok {
 		switch domainErr.Type {
 		case model.ErrorTypeValidation:
-			writeError(w, http.StatusBadRequest, domainErr.Message)
+			writeError(w, r, http.StatusBadRequest, domainErr.Message)
 		case model.ErrorTypeNotFound:
-			writeError(w, http.StatusNotFound, domainErr.Message)
+			writeError(w, r, http.StatusNotFound, domainErr.Message)
+		case model.ErrorTypeUnauthorized:
+			writeError(w, r, http.StatusUnauthorized, domainErr.Message)
+		case model.ErrorTypeForbidden:
+			writeError(w, r, http.StatusForbidden, domainErr.Message)
 		default:
-			writeError(w, http.StatusNotFound, domainErr.Message)
+			writeError(w, r, http.StatusNotFound, domainErr.Message)
 		}
 		return
 	}
-	writeError(w, http.StatusInternalServerError, "internal server error")
+	writeError(w, r, http.StatusInternalServerError, "internal server error")
 }
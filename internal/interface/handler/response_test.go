@@ -0,0 +1,62 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"GO-API/internal/interface/handler"
+	"GO-API/internal/interface/middleware"
+	"GO-API/internal/pkg/logger"
+)
+
+func TestErrorResponseLogsRequestContext(t *testing.T) {
+	var buf bytes.Buffer
+	if err := logger.Init(&buf, logger.FormatJSON, slog.LevelInfo); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { logger.Init(os.Stdout, logger.FormatText, slog.LevelInfo) })
+
+	router := mux.NewRouter()
+	handler.NewMerchantHandler(nil).RegisterRoutes(router)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/merchant", nil)
+	req.Header.Set(handler.RequestIDHeader, "req-123")
+	rec := httptest.NewRecorder()
+	middleware.RequestID(router).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+	var body handler.ErrorResponse
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	if body.RequestID != "req-123" {
+		t.Errorf("response request_id = %q, want req-123", body.RequestID)
+	}
+
+	var line map[string]any
+	for _, raw := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(raw), &entry); err != nil {
+			t.Fatalf("log line %q: %v", raw, err)
+		}
+		if entry["msg"] == "Error response" {
+			line = entry
+		}
+	}
+	if line == nil {
+		t.Fatalf("no error response log line in %s", buf.String())
+	}
+	if line["request_id"] != "req-123" {
+		t.Errorf("log request_id = %v, want req-123", line["request_id"])
+	}
+}
@@ -0,0 +1,201 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"GO-API/internal/pkg/auth"
+	"GO-API/internal/pkg/logger"
+	"GO-API/internal/usecase"
+)
+
+type SubscriptionHandler struct {
+	subscriptionUseCase *usecase.SubscriptionUseCase
+}
+
+func NewSubscriptionHandler(su *usecase.SubscriptionUseCase) *SubscriptionHandler {
+	return &SubscriptionHandler{
+		subscriptionUseCase: su,
+	}
+}
+
+type CreatePlanRequest struct {
+	Name            string `json:"name"`
+	Amount          int64  `json:"amount"`
+	Currency        string `json:"currency"`
+	Interval        string `json:"interval"`
+	IntervalCount   int    `json:"interval_count"`
+	TrialPeriodDays int    `json:"trial_period_days"`
+}
+
+type CreateSubscriptionRequest struct {
+	CustomerID      string     `json:"customer_id" log:"hash"`
+	PlanID          string     `json:"plan_id"`
+	PaymentMethod   string     `json:"payment_method"`
+	TrialPeriodDays *int       `json:"trial_period_days"`
+	BillingAnchor   *time.Time `json:"billing_anchor"`
+}
+
+type CancelSubscriptionRequest struct {
+	AtPeriodEnd bool `json:"at_period_end"`
+}
+
+func (h *SubscriptionHandler) RegisterRoutes(r *mux.Router) {
+	r.HandleFunc("/api/v1/plans", requirePermission(auth.PermissionAdmin, h.CreatePlan)).Methods(http.MethodPost)
+	r.HandleFunc("/api/v1/plans/{id}", requirePermission(auth.PermissionPaymentsRead, h.GetPlan)).Methods(http.MethodGet)
+	r.HandleFunc("/api/v1/plans", requirePermission(auth.PermissionPaymentsRead, h.ListPlans)).Methods(http.MethodGet)
+	r.HandleFunc("/api/v1/subscriptions", requirePermission(auth.PermissionPaymentsWrite, h.CreateSubscription)).Methods(http.MethodPost)
+	r.HandleFunc("/api/v1/subscriptions/{id}", requirePermission(auth.PermissionPaymentsRead, h.GetSubscription)).Methods(http.MethodGet)
+	r.HandleFunc("/api/v1/subscriptions", requirePermission(auth.PermissionPaymentsRead, h.ListSubscriptions)).Methods(http.MethodGet)
+	r.HandleFunc("/api/v1/subscriptions/{id}/cancel", requirePermission(auth.PermissionPaymentsWrite, h.CancelSubscription)).Methods(http.MethodPost)
+}
+
+func (h *SubscriptionHandler) CreatePlan(w http.ResponseWriter, r *http.Request) {
+	logger.InfoContext(r.Context(), "Received create plan request")
+
+	var req CreatePlanRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.ErrorContext(r.Context(), "Failed to decode request body", "error", err)
+		writeError(w, r, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	plan, err := h.subscriptionUseCase.CreatePlan(r.Context(), usecase.CreatePlanInput{
+		Name:            req.Name,
+		Amount:          req.Amount,
+		Currency:        req.Currency,
+		Interval:        req.Interval,
+		IntervalCount:   req.IntervalCount,
+		TrialPeriodDays: req.TrialPeriodDays,
+	})
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Failed to create plan", "error", err)
+		handleError(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, http.StatusCreated, plan)
+}
+
+func (h *SubscriptionHandler) GetPlan(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	plan, err := h.subscriptionUseCase.GetPlan(r.Context(), id)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Error getting plan", "error", err)
+		handleError(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, plan)
+}
+
+func (h *SubscriptionHandler) ListPlans(w http.ResponseWriter, r *http.Request) {
+	limit, offset := parsePagination(r)
+
+	plans, err := h.subscriptionUseCase.ListPlans(r.Context(), limit, offset)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Failed to fetch plans", "error", err)
+		handleError(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, plans)
+}
+
+func (h *SubscriptionHandler) CreateSubscription(w http.ResponseWriter, r *http.Request) {
+	logger.InfoContext(r.Context(), "Received create subscription request")
+
+	var req CreateSubscriptionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.ErrorContext(r.Context(), "Failed to decode request body", "error", err)
+		writeError(w, r, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	subscription, err := h.subscriptionUseCase.CreateSubscription(r.Context(), usecase.CreateSubscriptionInput{
+		CustomerID:      req.CustomerID,
+		PlanID:          req.PlanID,
+		PaymentMethod:   req.PaymentMethod,
+		TrialPeriodDays: req.TrialPeriodDays,
+		BillingAnchor:   req.BillingAnchor,
+	})
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Failed to create subscription", "error", err)
+		handleError(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, http.StatusCreated, subscription)
+}
+
+func (h *SubscriptionHandler) GetSubscription(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	subscription, err := h.subscriptionUseCase.GetSubscription(r.Context(), id)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Error getting subscription", "error", err)
+		handleError(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, subscription)
+}
+
+func (h *SubscriptionHandler) ListSubscriptions(w http.ResponseWriter, r *http.Request) {
+	limit, offset := parsePagination(r)
+
+	subscriptions, err := h.subscriptionUseCase.ListSubscriptions(r.Context(), limit, offset)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Failed to fetch subscriptions", "error", err)
+		handleError(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, subscriptions)
+}
+
+func (h *SubscriptionHandler) CancelSubscription(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	var req CancelSubscriptionRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			logger.ErrorContext(r.Context(), "Failed to decode request body", "error", err)
+			writeError(w, r, http.StatusBadRequest, "invalid request body")
+			return
+		}
+	}
+
+	subscription, err := h.subscriptionUseCase.CancelSubscription(r.Context(), id, req.AtPeriodEnd)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Failed to cancel subscription", "error", err)
+		handleError(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, subscription)
+}
+
+func parsePagination(r *http.Request) (int, int) {
+	limit := 10
+	offset := 0
+
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+			limit = l
+		}
+	}
+
+	if offsetStr := r.URL.Query().Get("offset"); offsetStr != "" {
+		if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
+			offset = o
+		}
+	}
+
+	return limit, offset
+}
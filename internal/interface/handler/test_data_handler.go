@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"GO-API/internal/pkg/auth"
+	"GO-API/internal/pkg/logger"
+	"GO-API/internal/usecase"
+)
+
+type TestDataHandler struct {
+	testDataUseCase *usecase.TestDataUseCase
+}
+
+func NewTestDataHandler(tu *usecase.TestDataUseCase) *TestDataHandler {
+	return &TestDataHandler{
+		testDataUseCase: tu,
+	}
+}
+
+func (h *TestDataHandler) RegisterRoutes(r *mux.Router) {
+	r.HandleFunc("/api/v1/test-data", requirePermission(auth.PermissionAdmin, h.DeleteTestData)).Methods(http.MethodDelete)
+}
+
+// DeleteTestData deletes the merchant's test mode payments, receipts,
+// subscriptions, plans and invoices. Test mode API keys and API clients are
+// kept; revoke them through their own endpoints.
+func (h *TestDataHandler) DeleteTestData(w http.ResponseWriter, r *http.Request) {
+	logger.InfoContext(r.Context(), "Received delete test data request")
+
+	result, err := h.testDataUseCase.DeleteTestData(r.Context())
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Failed to delete test data", "error", err)
+		handleError(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, result)
+}
@@ -0,0 +1,203 @@
+package middleware
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"GO-API/internal/pkg/logger"
+)
+
+// Access log formats.
+const (
+	// AccessLogJSON writes one structured record per request through the
+	// application logger.
+	AccessLogJSON = "json"
+	// AccessLogCombined writes Apache combined log format lines to the
+	// configured output.
+	AccessLogCombined = "combined"
+)
+
+// DefaultSlowRequestThreshold is used when AccessLogConfig leaves
+// SlowThreshold unset.
+const DefaultSlowRequestThreshold = time.Second
+
+type AccessLogConfig struct {
+	Format string
+	// SlowThreshold is the latency above which a request is logged as a
+	// warning.
+	SlowThreshold time.Duration
+	// Output receives combined format lines.
+	Output io.Writer
+}
+
+// accessLogEntry is shared through the request context so that middleware
+// further down the chain can fill in details the access log cannot see,
+// such as the authenticated principal.
+type accessLogEntry struct {
+	subject    string
+	merchantID string
+}
+
+type accessLogContextKey struct{}
+
+// setAccessLogPrincipal records who made the request for the access log.
+func setAccessLogPrincipal(ctx context.Context, merchantID, subject string) {
+	if entry, ok := ctx.Value(accessLogContextKey{}).(*accessLogEntry); ok {
+		entry.merchantID = merchantID
+		entry.subject = subject
+	}
+}
+
+// AccessLog logs every request once it has completed with its status,
+// response size, latency, client and path template. Templates such as
+// /api/v1/payments/{id} are logged instead of raw paths so that log based
+// metrics stay low in cardinality; requests that match no route, such as
+// 404s, 405s and CORS preflights, are logged with their raw path. It wraps
+// the router, which must have RecordRoute installed, so that those requests
+// are logged too.
+func AccessLog(cfg AccessLogConfig) func(http.Handler) http.Handler {
+	if cfg.SlowThreshold <= 0 {
+		cfg.SlowThreshold = DefaultSlowRequestThreshold
+	}
+	var mu sync.Mutex
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			entry := &accessLogEntry{}
+			sw := &statusWriter{ResponseWriter: w}
+			r, route := withMatchedRoute(r)
+
+			next.ServeHTTP(sw, r.WithContext(context.WithValue(r.Context(), accessLogContextKey{}, entry)))
+
+			duration := time.Since(start)
+			slow := duration > cfg.SlowThreshold
+
+			if cfg.Format == AccessLogCombined && cfg.Output != nil {
+				mu.Lock()
+				fmt.Fprintln(cfg.Output, combinedLogLine(r, route, sw, entry, start))
+				mu.Unlock()
+				if slow {
+					logger.WarnContext(r.Context(), "Slow request", "method", r.Method, "route", accessLogRoute(r, route), "duration", duration)
+				}
+				return
+			}
+
+			level := slog.LevelInfo
+			if slow {
+				level = slog.LevelWarn
+			}
+			logger.LogContext(r.Context(), level, "Request completed",
+				"method", r.Method,
+				"route", accessLogRoute(r, route),
+				"status", sw.Status(),
+				"bytes", sw.bytes,
+				"duration", duration,
+				"slow", slow,
+				"client_ip", remoteIP(r),
+				"user_agent", r.UserAgent(),
+				"merchant_id", entry.merchantID,
+				"subject", entry.subject,
+			)
+		})
+	}
+}
+
+// accessLogRoute is the path template of the matched route, or the raw path
+// if no route matched.
+func accessLogRoute(r *http.Request, route *matchedRoute) string {
+	if template := routeTemplate(r, route); template != "" {
+		return template
+	}
+	return r.URL.Path
+}
+
+// combinedLogLine formats a request in the Apache combined log format, with
+// the path template in place of the request URI.
+func combinedLogLine(r *http.Request, route *matchedRoute, sw *statusWriter, entry *accessLogEntry, start time.Time) string {
+	user := "-"
+	if entry.subject != "" {
+		user = entry.subject
+	}
+	size := "-"
+	if sw.bytes > 0 {
+		size = strconv.FormatInt(sw.bytes, 10)
+	}
+	return fmt.Sprintf(`%s - %s [%s] "%s %s %s" %d %s %q %q`,
+		remoteIP(r), user, start.Format("02/Jan/2006:15:04:05 -0700"),
+		r.Method, accessLogRoute(r, route), r.Proto, sw.Status(), size,
+		orDash(r.Referer()), orDash(r.UserAgent()))
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// statusWriter records the status code and number of bytes written. It
+// passes Flush and Hijack through so streaming and upgraded connections keep
+// working behind the access log.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += int64(n)
+	return n, err
+}
+
+// Status is the status code sent, or 200 if the handler wrote nothing.
+func (w *statusWriter) Status() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
+
+func (w *statusWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		if w.status == 0 {
+			w.status = http.StatusOK
+		}
+		f.Flush()
+	}
+}
+
+func (w *statusWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("response writer does not support hijacking")
+	}
+	if w.status == 0 {
+		w.status = http.StatusSwitchingProtocols
+	}
+	return h.Hijack()
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,66 @@
+package middleware_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"GO-API/internal/interface/middleware"
+)
+
+// newServer wires the observing middleware around a router as main does.
+func newServer(accessLog *bytes.Buffer, reporter middleware.ErrorReporter, routes func(*mux.Router)) http.Handler {
+	router := mux.NewRouter()
+	router.Use(middleware.RecordRoute)
+	routes(router)
+
+	var h http.Handler = middleware.CORS(middleware.CORSConfig{Default: middleware.CORSPolicy{
+		AllowedOrigins: []string{"https://app.example.com"},
+		AllowedMethods: []string{http.MethodGet},
+	}})(router)
+	h = middleware.Recover(reporter)(h)
+	h = middleware.Metrics(h)
+	h = middleware.AccessLog(middleware.AccessLogConfig{
+		Format: middleware.AccessLogCombined,
+		Output: accessLog,
+	})(h)
+	h = middleware.Tracing(h)
+	return middleware.RequestID(h)
+}
+
+func TestAccessLogRecordsUnmatchedRequests(t *testing.T) {
+	var accessLog bytes.Buffer
+	server := newServer(&accessLog, middleware.LogReporter{}, func(r *mux.Router) {
+		r.HandleFunc("/api/v1/payments/{id}", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		}).Methods(http.MethodGet)
+	})
+
+	preflight := httptest.NewRequest(http.MethodOptions, "/api/v1/payments/pay_1", nil)
+	preflight.Header.Set("Origin", "https://app.example.com")
+	preflight.Header.Set("Access-Control-Request-Method", http.MethodGet)
+
+	tests := []struct {
+		name string
+		req  *http.Request
+		want string
+	}{
+		{"matched", httptest.NewRequest(http.MethodGet, "/api/v1/payments/pay_1", nil), `"GET /api/v1/payments/{id} HTTP/1.1" 204 `},
+		{"not found", httptest.NewRequest(http.MethodGet, "/wp-login.php", nil), `"GET /wp-login.php HTTP/1.1" 404 `},
+		{"method not allowed", httptest.NewRequest(http.MethodDelete, "/api/v1/payments/pay_1", nil), `"DELETE /api/v1/payments/pay_1 HTTP/1.1" 405 `},
+		{"preflight", preflight, `"OPTIONS /api/v1/payments/pay_1 HTTP/1.1" 204 `},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accessLog.Reset()
+			server.ServeHTTP(httptest.NewRecorder(), tt.req)
+			if line := accessLog.String(); !strings.Contains(line, tt.want) {
+				t.Errorf("access log = %q, want it to contain %q", line, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"GO-API/internal/domain/model"
+	"GO-API/internal/pkg/auth"
+	"GO-API/internal/pkg/logger"
+)
+
+const bearerRealm = "GO-API"
+
+// APIKeyAuthenticator resolves a merchant secret key presented by a client at
+// remoteIP to the principal it authenticates.
+type APIKeyAuthenticator interface {
+	AuthenticateAPIKey(ctx context.Context, secret string, remoteIP string) (*auth.Principal, error)
+}
+
+// Authenticate validates the Bearer credential of every request except those
+// whose path is listed in publicPaths and stores the resulting principal in
+// the request context. Public paths ending in "/" match every path below them. The credential is either a JWT or a merchant API key
+// (sk_live_/sk_test_). Failures are answered with RFC 6750 WWW-Authenticate
+// challenges.
+func Authenticate(jwtAuth *auth.JWTAuth, apiKeys APIKeyAuthenticator, publicPaths ...string) func(http.Handler) http.Handler {
+	public := pathMatcher(publicPaths)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if public(r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			token, ok := bearerToken(r)
+			if !ok {
+				logger.InfoContext(r.Context(), "Missing bearer token", "method", r.Method, "path", r.URL.Path)
+				w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s"`, bearerRealm))
+				writeError(w, r, http.StatusUnauthorized, "authentication required")
+				return
+			}
+
+			var principal *auth.Principal
+			if model.IsAPIKey(token) {
+				var err error
+				principal, err = apiKeys.AuthenticateAPIKey(r.Context(), token, remoteIP(r))
+				if err != nil {
+					rejectAPIKey(w, r, err)
+					return
+				}
+			} else {
+				claims, err := jwtAuth.ValidateToken(r.Context(), token)
+				if err != nil {
+					logger.InfoContext(r.Context(), "Rejected bearer token", "error", err)
+					invalidToken(w, r, "the access token is invalid or expired")
+					return
+				}
+				principal = auth.NewJWTPrincipal(claims)
+				if principal.MerchantID == "" {
+					// Tokens without a merchant_id claim predate multi-tenancy.
+					principal.MerchantID = model.DefaultMerchantID
+				}
+			}
+
+			setAccessLogPrincipal(r.Context(), principal.MerchantID, principal.Subject)
+			ctx := auth.WithPrincipal(r.Context(), principal)
+			ctx = logger.WithMerchantID(ctx, principal.MerchantID)
+			ctx = logger.WithUserID(ctx, principal.Subject)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// pathMatcher reports whether a path is one of paths, where paths ending in
+// "/" match every path below them.
+func pathMatcher(paths []string) func(string) bool {
+	exact := make(map[string]bool, len(paths))
+	var prefixes []string
+	for _, path := range paths {
+		if strings.HasSuffix(path, "/") {
+			prefixes = append(prefixes, path)
+			continue
+		}
+		exact[path] = true
+	}
+
+	return func(path string) bool {
+		if exact[path] {
+			return true
+		}
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(path, prefix) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func rejectAPIKey(w http.ResponseWriter, r *http.Request, err error) {
+	var domainErr *model.Error
+	if !errors.As(err, &domainErr) {
+		logger.ErrorContext(r.Context(), "Failed to authenticate API key", "error", err)
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	logger.InfoContext(r.Context(), "Rejected API key", "error", err)
+	if domainErr.Type == model.ErrorTypeForbidden {
+		writeError(w, r, http.StatusForbidden, domainErr.Message)
+		return
+	}
+	invalidToken(w, r, "the api key is invalid, expired or revoked")
+}
+
+func invalidToken(w http.ResponseWriter, r *http.Request, description string) {
+	w.Header().Set("WWW-Authenticate", fmt.Sprintf(
+		`Bearer realm="%s", error="invalid_token", error_description="%s"`, bearerRealm, description))
+	writeError(w, r, http.StatusUnauthorized, "invalid token")
+}
+
+func bearerToken(r *http.Request) (string, bool) {
+	header := r.Header.Get("Authorization")
+	scheme, token, found := strings.Cut(header, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// remoteIP is the address of the directly connected peer. Forwarding headers
+// are not trusted, so API key allowlists must list the proxy's egress address
+// when the service runs behind one.
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
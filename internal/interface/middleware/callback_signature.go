@@ -0,0 +1,130 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"GO-API/internal/pkg/logger"
+)
+
+// Headers carrying the signature of an inbound processor callback. The
+// signature is the hex encoded HMAC-SHA256, keyed with the provider's secret,
+// of the timestamp, a "." and the raw request body.
+const (
+	CallbackTimestampHeader = "X-Callback-Timestamp"
+	CallbackSignatureHeader = "X-Callback-Signature"
+)
+
+const (
+	DefaultCallbackTolerance = 5 * time.Minute
+	maxCallbackBodyBytes     = 1 << 20
+)
+
+// CallbackNonceStore remembers signatures that have been accepted so a
+// captured callback cannot be replayed within the tolerance window. Release
+// forgets a nonce whose callback failed so the provider's retry is accepted.
+type CallbackNonceStore interface {
+	Use(ctx context.Context, provider string, nonce string, expiresAt time.Time) (bool, error)
+	Release(ctx context.Context, provider string, nonce string) error
+}
+
+// VerifyCallbackSignature authenticates callbacks sent to routes with a
+// {provider} variable using the provider's entry in secrets. Callbacks whose
+// timestamp is more than tolerance away from now, or whose signature has been
+// seen before, are rejected. A callback the handler fails with a 5xx does not
+// count as seen.
+func VerifyCallbackSignature(secrets map[string]string, nonces CallbackNonceStore, tolerance time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			provider := mux.Vars(r)["provider"]
+			secret, ok := secrets[provider]
+			if !ok || secret == "" {
+				logger.InfoContext(r.Context(), "Callback for unknown provider", "provider", provider)
+				writeError(w, r, http.StatusNotFound, "unknown callback provider")
+				return
+			}
+
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxCallbackBodyBytes))
+			if err != nil {
+				writeError(w, r, http.StatusBadRequest, "invalid request body")
+				return
+			}
+
+			timestamp := r.Header.Get(CallbackTimestampHeader)
+			signature := r.Header.Get(CallbackSignatureHeader)
+			signedAt, err := strconv.ParseInt(timestamp, 10, 64)
+			if err != nil || signature == "" {
+				logger.InfoContext(r.Context(), "Callback without signature headers", "provider", provider)
+				writeError(w, r, http.StatusUnauthorized, "missing callback signature")
+				return
+			}
+
+			now := time.Now()
+			sentAt := time.Unix(signedAt, 0)
+			if sentAt.Before(now.Add(-tolerance)) || sentAt.After(now.Add(tolerance)) {
+				logger.InfoContext(r.Context(), "Callback outside tolerance window", "provider", provider, "timestamp", signedAt)
+				writeError(w, r, http.StatusUnauthorized, "callback timestamp is outside the tolerance window")
+				return
+			}
+
+			mac, ok := verifyCallbackMAC(secret, timestamp, body, signature)
+			if !ok {
+				logger.InfoContext(r.Context(), "Callback with invalid signature", "provider", provider)
+				writeError(w, r, http.StatusUnauthorized, "invalid callback signature")
+				return
+			}
+
+			// The nonce is the canonical encoding of the MAC, so re-encoding the
+			// header (e.g. in upper case) does not make a replay look new.
+			nonce := hex.EncodeToString(mac)
+			fresh, err := nonces.Use(r.Context(), provider, nonce, sentAt.Add(tolerance))
+			if err != nil {
+				logger.ErrorContext(r.Context(), "Failed to record callback nonce", "error", err)
+				writeError(w, r, http.StatusInternalServerError, "internal server error")
+				return
+			}
+			if !fresh {
+				logger.InfoContext(r.Context(), "Replayed callback rejected", "provider", provider)
+				writeError(w, r, http.StatusConflict, "callback has already been received")
+				return
+			}
+
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			sw := &statusWriter{ResponseWriter: w}
+			next.ServeHTTP(sw, r)
+
+			if sw.Status() >= http.StatusInternalServerError {
+				if err := nonces.Release(r.Context(), provider, nonce); err != nil {
+					logger.ErrorContext(r.Context(), "Failed to release callback nonce", "provider", provider, "error", err)
+				}
+			}
+		})
+	}
+}
+
+// verifyCallbackMAC checks signature against the expected MAC and returns the
+// decoded MAC when it matches.
+func verifyCallbackMAC(secret, timestamp string, body []byte, signature string) ([]byte, bool) {
+	expected, err := hex.DecodeString(signature)
+	if err != nil {
+		return nil, false
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	if !hmac.Equal(mac.Sum(nil), expected) {
+		return nil, false
+	}
+	return expected, true
+}
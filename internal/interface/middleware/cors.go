@@ -1,18 +1,170 @@
 package middleware
 
-import "net/http"
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
 
-func CORS(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT ,DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Accept, Authorization, Content-Type")
+// CORSPolicy describes which cross-origin requests browsers may make.
+// AllowedOrigins entries are exact origins ("https://app.example.com"),
+// wildcard subdomains ("https://*.example.com", which does not match the
+// apex) or "*" for any origin. AllowedHeaders may be "*" to accept whatever
+// the browser asks for. MaxAge is how long, in seconds, a preflight response
+// may be cached.
+type CORSPolicy struct {
+	AllowedOrigins   []string `json:"allowed_origins"`
+	AllowedMethods   []string `json:"allowed_methods"`
+	AllowedHeaders   []string `json:"allowed_headers"`
+	ExposedHeaders   []string `json:"exposed_headers"`
+	AllowCredentials bool     `json:"allow_credentials"`
+	MaxAge           int      `json:"max_age"`
+}
+
+// CORSConfig applies Default to every path except those under a prefix in
+// Routes, where the policy of the longest matching prefix replaces it.
+type CORSConfig struct {
+	Default CORSPolicy
+	Routes  map[string]CORSPolicy
+}
+
+// CORS answers preflight requests and adds CORS headers to responses for
+// allowed origins. The allowed origin is echoed rather than sent as "*", so
+// responses always vary by Origin. It must wrap the router rather than be
+// installed with Use, since preflight requests do not match routes that are
+// restricted to other methods.
+func CORS(cfg CORSConfig) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			policy := cfg.policy(r.URL.Path)
+			origin := r.Header.Get("Origin")
+			preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+
+			h := w.Header()
+			h.Add("Vary", "Origin")
+			if preflight {
+				h.Add("Vary", "Access-Control-Request-Method")
+				h.Add("Vary", "Access-Control-Request-Headers")
+			}
+
+			if origin == "" || !policy.allowsOrigin(origin) {
+				if preflight {
+					w.WriteHeader(http.StatusNoContent)
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if !preflight {
+				h.Set("Access-Control-Allow-Origin", origin)
+				if policy.AllowCredentials {
+					h.Set("Access-Control-Allow-Credentials", "true")
+				}
+				if len(policy.ExposedHeaders) > 0 {
+					h.Set("Access-Control-Expose-Headers", strings.Join(policy.ExposedHeaders, ", "))
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
 
-		if r.Method == "OPTIONS" {
-			w.WriteHeader(http.StatusOK)
-			return
+			method := r.Header.Get("Access-Control-Request-Method")
+			requested := splitHeaderList(r.Header.Get("Access-Control-Request-Headers"))
+			if !policy.allowsMethod(method) || !policy.allowsHeaders(requested) {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+
+			h.Set("Access-Control-Allow-Origin", origin)
+			h.Set("Access-Control-Allow-Methods", strings.Join(policy.AllowedMethods, ", "))
+			if len(requested) > 0 {
+				h.Set("Access-Control-Allow-Headers", strings.Join(requested, ", "))
+			}
+			if policy.AllowCredentials {
+				h.Set("Access-Control-Allow-Credentials", "true")
+			}
+			if policy.MaxAge > 0 {
+				h.Set("Access-Control-Max-Age", strconv.Itoa(policy.MaxAge))
+			}
+			w.WriteHeader(http.StatusNoContent)
+		})
+	}
+}
+
+func (c CORSConfig) policy(path string) CORSPolicy {
+	policy := c.Default
+	matched := ""
+	for prefix, override := range c.Routes {
+		if strings.HasPrefix(path, prefix) && len(prefix) > len(matched) {
+			policy = override
+			matched = prefix
 		}
+	}
+	return policy
+}
+
+// allowsOrigin ignores "*" in policies that allow credentials, since echoing
+// any origin with credentials would let every site make authenticated calls.
+func (p CORSPolicy) allowsOrigin(origin string) bool {
+	for _, allowed := range p.AllowedOrigins {
+		if (allowed == "*" && !p.AllowCredentials) || strings.EqualFold(allowed, origin) || matchesWildcardOrigin(allowed, origin) {
+			return true
+		}
+	}
+	return false
+}
 
-		next.ServeHTTP(w, r)
-	})
+// matchesWildcardOrigin reports whether origin is a subdomain of a pattern
+// such as "https://*.example.com" with the same scheme and port.
+func matchesWildcardOrigin(pattern, origin string) bool {
+	scheme, host, found := strings.Cut(pattern, "://*.")
+	if !found {
+		return false
+	}
+
+	u, err := url.Parse(origin)
+	if err != nil || !strings.EqualFold(u.Scheme, scheme) {
+		return false
+	}
+	suffix := "." + strings.ToLower(host)
+	actual := strings.ToLower(u.Host)
+	return strings.HasSuffix(actual, suffix) && len(actual) > len(suffix)
+}
+
+func (p CORSPolicy) allowsMethod(method string) bool {
+	for _, allowed := range p.AllowedMethods {
+		if strings.EqualFold(allowed, method) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p CORSPolicy) allowsHeaders(requested []string) bool {
+	for _, header := range requested {
+		if !p.allowsHeader(header) {
+			return false
+		}
+	}
+	return true
+}
+
+func (p CORSPolicy) allowsHeader(header string) bool {
+	for _, allowed := range p.AllowedHeaders {
+		if allowed == "*" || strings.EqualFold(allowed, header) {
+			return true
+		}
+	}
+	return false
+}
+
+func splitHeaderList(value string) []string {
+	var values []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
 }
@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"GO-API/internal/pkg/metrics"
+)
+
+// Metrics counts requests and observes their latency per route template and
+// status code. Requests that match no route are counted as UnmatchedRoute.
+// Like AccessLog it wraps the router, which must have RecordRoute installed.
+func Metrics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		sw := &statusWriter{ResponseWriter: w}
+		r, route := withMatchedRoute(r)
+
+		next.ServeHTTP(sw, r)
+
+		metrics.ObserveHTTPRequest(r.Method, routeLabel(r, route), sw.Status(), time.Since(start))
+	})
+}
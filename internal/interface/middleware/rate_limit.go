@@ -0,0 +1,317 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"GO-API/internal/pkg/auth"
+	"GO-API/internal/pkg/logger"
+	"GO-API/internal/pkg/ratelimit"
+)
+
+// planCacheTTL bounds how long a merchant's plan is cached, so that plan
+// changes take effect without a restart and without a lookup per request.
+const planCacheTTL = time.Minute
+
+// RateLimitPlanResolver returns the plan of a merchant.
+type RateLimitPlanResolver interface {
+	RateLimitPlan(ctx context.Context, merchantID string) (string, error)
+}
+
+// RateLimitConfig configures RateLimit. Every client has one bucket governed
+// by the limit of its merchant's plan, or Default when the plan has none.
+// Routes, keyed by method and path template (e.g. "POST /api/v1/payments"),
+// additionally get a bucket per client with their own limit.
+type RateLimitConfig struct {
+	Default ratelimit.Limit
+	Plans   map[string]ratelimit.Limit
+	Routes  map[string]ratelimit.Limit
+}
+
+type planCacheEntry struct {
+	plan      string
+	expiresAt time.Time
+}
+
+type rateLimitCheck struct {
+	key   string
+	limit ratelimit.Limit
+}
+
+type rateLimiter struct {
+	cfg   RateLimitConfig
+	store ratelimit.Store
+	plans RateLimitPlanResolver
+
+	mu        sync.Mutex
+	planCache map[string]planCacheEntry
+}
+
+// RateLimit throttles clients with token buckets. Clients are identified by
+// API key, else JWT subject, else remote IP, so it must run after
+// Authenticate. Responses carry RateLimit-Limit, RateLimit-Remaining and
+// RateLimit-Reset headers; throttled requests get 429 with Retry-After. If the
+// store fails the request is let through.
+func RateLimit(cfg RateLimitConfig, store ratelimit.Store, plans RateLimitPlanResolver) func(http.Handler) http.Handler {
+	limiter := &rateLimiter{
+		cfg:       cfg,
+		store:     store,
+		plans:     plans,
+		planCache: make(map[string]planCacheEntry),
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodOptions {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if !limiter.allow(w, r) {
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// allow counts the request against the client's buckets and writes the
+// rate limit headers of the most constrained one. It answers throttled
+// requests itself and returns false for them.
+func (l *rateLimiter) allow(w http.ResponseWriter, r *http.Request) bool {
+	now := time.Now()
+	client := clientKey(r)
+
+	checks := []rateLimitCheck{{key: client, limit: l.clientLimit(r)}}
+	if route := routeKey(r); route != "" {
+		if limit, ok := l.cfg.Routes[route]; ok {
+			checks = append(checks, rateLimitCheck{key: client + "|" + route, limit: limit})
+		}
+	}
+
+	var tightest *ratelimit.Result
+	for _, check := range checks {
+		result, err := l.store.Take(r.Context(), check.key, check.limit, now)
+		if err != nil {
+			logger.ErrorContext(r.Context(), "Rate limit store failed, allowing request", "error", err)
+			continue
+		}
+		if tightest == nil || !result.Allowed || (tightest.Allowed && result.Remaining < tightest.Remaining) {
+			tightest = &result
+		}
+		if !result.Allowed {
+			break
+		}
+	}
+	if tightest == nil {
+		return true
+	}
+
+	setRateLimitHeaders(w.Header(), *tightest)
+	if !tightest.Allowed {
+		logger.WarnContext(r.Context(), "Rate limit exceeded", "client", client, "method", r.Method, "path", r.URL.Path)
+		tooManyRequests(w, r, tightest.RetryAfter)
+		return false
+	}
+	return true
+}
+
+func setRateLimitHeaders(h http.Header, result ratelimit.Result) {
+	h.Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+	h.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	h.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+}
+
+func tooManyRequests(w http.ResponseWriter, r *http.Request, retryAfter time.Duration) {
+	w.Header().Set("Retry-After", strconv.Itoa(ceilSeconds(retryAfter)))
+	writeError(w, r, http.StatusTooManyRequests, "rate limit exceeded")
+}
+
+func (l *rateLimiter) clientLimit(r *http.Request) ratelimit.Limit {
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	if !ok || l.plans == nil || len(l.cfg.Plans) == 0 {
+		return l.cfg.Default
+	}
+	if limit, ok := l.cfg.Plans[l.plan(r.Context(), principal.MerchantID)]; ok {
+		return limit
+	}
+	return l.cfg.Default
+}
+
+func (l *rateLimiter) plan(ctx context.Context, merchantID string) string {
+	now := time.Now()
+
+	l.mu.Lock()
+	entry, ok := l.planCache[merchantID]
+	l.mu.Unlock()
+	if ok && now.Before(entry.expiresAt) {
+		return entry.plan
+	}
+
+	plan, err := l.plans.RateLimitPlan(ctx, merchantID)
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to resolve rate limit plan for merchant", "merchant_id", merchantID, "error", err)
+		return ""
+	}
+
+	l.mu.Lock()
+	l.planCache[merchantID] = planCacheEntry{plan: plan, expiresAt: now.Add(planCacheTTL)}
+	l.mu.Unlock()
+	return plan
+}
+
+// clientKey identifies the client a request is counted against.
+func clientKey(r *http.Request) string {
+	if principal, ok := auth.PrincipalFromContext(r.Context()); ok {
+		if principal.APIKeyID != "" {
+			return "key:" + principal.APIKeyID
+		}
+		return fmt.Sprintf("sub:%s:%s", principal.MerchantID, principal.Subject)
+	}
+	return "ip:" + remoteIP(r)
+}
+
+// routeKey is the method and path template of the matched route, so that all
+// requests to e.g. /api/v1/payments/{id} share a bucket.
+func routeKey(r *http.Request) string {
+	template := pathTemplate(r)
+	if template == "" {
+		return ""
+	}
+	return r.Method + " " + template
+}
+
+// pathTemplate is the path template of the matched route, or "" if no route
+// matched.
+func pathTemplate(r *http.Request) string {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return ""
+	}
+	template, err := route.GetPathTemplate()
+	if err != nil {
+		return ""
+	}
+	return template
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+type ipRateLimiter struct {
+	limit ratelimit.Limit
+	store ratelimit.Store
+
+	mu sync.Mutex
+	// blocked holds, per IP, when the IP's bucket refills after failed
+	// credentials exhausted it. Credentialed requests cannot be counted
+	// until Authenticate has judged them, so this lets later attempts be
+	// refused up front.
+	blocked map[string]time.Time
+}
+
+// RateLimitUnauthenticated throttles, per remote IP, requests that carry no
+// bearer credential and requests whose credential is rejected with 401, so
+// that token guessing and anonymous floods are limited before Authenticate
+// and RateLimit spend work on them. It must run before Authenticate. Requests
+// to exemptPaths, which follow the publicPaths rules of Authenticate, are not
+// counted. If the store fails the request is let through.
+func RateLimitUnauthenticated(limit ratelimit.Limit, store ratelimit.Store, exemptPaths ...string) func(http.Handler) http.Handler {
+	limiter := &ipRateLimiter{
+		limit:   limit,
+		store:   store,
+		blocked: make(map[string]time.Time),
+	}
+	exempt := pathMatcher(exemptPaths)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodOptions || exempt(r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ip := remoteIP(r)
+			if _, ok := bearerToken(r); !ok {
+				if !limiter.allowAnonymous(w, r, ip) {
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if retryAfter, ok := limiter.blockedFor(ip, time.Now()); ok {
+				logger.WarnContext(r.Context(), "Rate limit exceeded", "client", "ip:"+ip, "method", r.Method, "path", r.URL.Path)
+				tooManyRequests(w, r, retryAfter)
+				return
+			}
+
+			sw := &statusWriter{ResponseWriter: w}
+			next.ServeHTTP(sw, r)
+			if sw.Status() == http.StatusUnauthorized {
+				limiter.countFailure(r, ip)
+			}
+		})
+	}
+}
+
+func (l *ipRateLimiter) allowAnonymous(w http.ResponseWriter, r *http.Request, ip string) bool {
+	result, err := l.store.Take(r.Context(), "anon:"+ip, l.limit, time.Now())
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Rate limit store failed, allowing request", "error", err)
+		return true
+	}
+
+	setRateLimitHeaders(w.Header(), result)
+	if !result.Allowed {
+		logger.WarnContext(r.Context(), "Rate limit exceeded", "client", "ip:"+ip, "method", r.Method, "path", r.URL.Path)
+		tooManyRequests(w, r, result.RetryAfter)
+		return false
+	}
+	return true
+}
+
+// countFailure charges a rejected credential to the IP's bucket, which it
+// shares with the IP's anonymous requests.
+func (l *ipRateLimiter) countFailure(r *http.Request, ip string) {
+	now := time.Now()
+	result, err := l.store.Take(r.Context(), "anon:"+ip, l.limit, now)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Rate limit store failed", "error", err)
+		return
+	}
+	if result.Allowed {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for blockedIP, until := range l.blocked {
+		if !now.Before(until) {
+			delete(l.blocked, blockedIP)
+		}
+	}
+	l.blocked[ip] = now.Add(result.RetryAfter)
+}
+
+func (l *ipRateLimiter) blockedFor(ip string, now time.Time) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	until, ok := l.blocked[ip]
+	if !ok {
+		return 0, false
+	}
+	if !now.Before(until) {
+		delete(l.blocked, ip)
+		return 0, false
+	}
+	return until.Sub(now), true
+}
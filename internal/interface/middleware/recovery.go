@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+
+	"GO-API/internal/pkg/logger"
+	"GO-API/internal/pkg/metrics"
+)
+
+// ErrorReporter receives panics recovered while serving requests, e.g. to
+// forward them to an error tracking service.
+type ErrorReporter interface {
+	Report(ctx context.Context, err error, stack []byte)
+}
+
+// LogReporter is the default ErrorReporter. It writes the error and stack to
+// the application log, along with the request ID and other context fields.
+type LogReporter struct{}
+
+func (LogReporter) Report(ctx context.Context, err error, stack []byte) {
+	logger.ErrorContext(ctx, "Panic while serving request", "error", err, "stack", string(stack))
+}
+
+// Recover turns a panic in any later handler into a 500 in the standard
+// error format and hands it to reporter. Wrap it inside RequestID so reports
+// carry the request ID, and inside AccessLog, Metrics and Tracing so that
+// they record the 500.
+func Recover(reporter ErrorReporter) func(http.Handler) http.Handler {
+	if reporter == nil {
+		reporter = LogReporter{}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			sw := &statusWriter{ResponseWriter: w}
+			defer func() {
+				recovered := recover()
+				if recovered == nil {
+					return
+				}
+				// http.ErrAbortHandler is how handlers abort a response on
+				// purpose; net/http handles it quietly.
+				if recovered == http.ErrAbortHandler {
+					panic(recovered)
+				}
+
+				err := fmt.Errorf("panic: %v", recovered)
+				if cause, ok := recovered.(error); ok {
+					err = fmt.Errorf("panic: %w", cause)
+				}
+				metrics.ObservePanic()
+				reporter.Report(r.Context(), err, debug.Stack())
+
+				// If the handler already started the response, the status
+				// cannot be changed; abort so the client sees a truncated
+				// response rather than a silently incomplete one.
+				if sw.status != 0 {
+					panic(http.ErrAbortHandler)
+				}
+				writeError(w, r, http.StatusInternalServerError, "internal server error")
+			}()
+
+			next.ServeHTTP(sw, r)
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package middleware_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel/codes"
+
+	"GO-API/internal/pkg/tracing/tracingtest"
+)
+
+type recordingReporter struct {
+	errs []error
+}
+
+func (r *recordingReporter) Report(ctx context.Context, err error, stack []byte) {
+	r.errs = append(r.errs, err)
+}
+
+func TestRecoverReportsPanicsToObservingMiddleware(t *testing.T) {
+	exporter := tracingtest.Record(t)
+	var accessLog bytes.Buffer
+	reporter := &recordingReporter{}
+
+	server := newServer(&accessLog, reporter, func(r *mux.Router) {
+		r.HandleFunc("/api/v1/payments/{id}", func(w http.ResponseWriter, r *http.Request) {
+			panic("boom")
+		}).Methods(http.MethodGet)
+	})
+
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/payments/pay_1", nil))
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want 500", rec.Code)
+	}
+	if len(reporter.errs) != 1 || !strings.Contains(reporter.errs[0].Error(), "boom") {
+		t.Errorf("reported errors = %v, want the panic", reporter.errs)
+	}
+	if line := accessLog.String(); !strings.Contains(line, `"GET /api/v1/payments/{id} HTTP/1.1" 500 `) {
+		t.Errorf("access log = %q, want a 500 line for the route", line)
+	}
+	span := tracingtest.Span(t, exporter, "GET /api/v1/payments/{id}")
+	if span.Status.Code != codes.Error {
+		t.Errorf("span status = %v, want error", span.Status.Code)
+	}
+}
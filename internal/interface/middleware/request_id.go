@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"GO-API/internal/interface/handler"
+	"GO-API/internal/pkg/logger"
+)
+
+// RequestIDHeader carries the request ID. A caller may supply one to
+// correlate its own logs with ours; otherwise one is generated.
+const RequestIDHeader = handler.RequestIDHeader
+
+// maxRequestIDLength bounds caller supplied request IDs.
+const maxRequestIDLength = 128
+
+// RequestID accepts or generates a request ID, stores it in the request
+// context so every log line written for the request includes it, and echoes
+// it in the response.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID(id) {
+			id = uuid.New().String()
+		}
+
+		w.Header().Set(RequestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(logger.WithRequestID(r.Context(), id)))
+	})
+}
+
+// validRequestID rejects IDs that are empty, too long or contain characters
+// that could forge log lines or headers.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		c := id[i]
+		if c <= ' ' || c > '~' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"GO-API/internal/interface/handler"
+	"GO-API/internal/pkg/logger"
+)
+
+func writeError(w http.ResponseWriter, r *http.Request, status int, message string) {
+	response := handler.ErrorResponse{
+		Error:     http.StatusText(status),
+		Message:   message,
+		Code:      fmt.Sprintf("ERR_%d", status),
+		RequestID: logger.RequestID(r.Context()),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		logger.ErrorContext(r.Context(), "Failed to encode response", "error", err)
+	}
+}
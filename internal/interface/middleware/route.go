@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+)
+
+// UnmatchedRoute labels requests that matched no route, such as 404s, 405s
+// and CORS preflights, in metrics and spans.
+const UnmatchedRoute = "unmatched"
+
+// matchedRoute is shared through the request context so that middleware
+// wrapping the router learns which route the router matched.
+type matchedRoute struct {
+	template string
+}
+
+type matchedRouteContextKey struct{}
+
+// withMatchedRoute returns r carrying a route holder, reusing the one an
+// outer middleware has already added.
+func withMatchedRoute(r *http.Request) (*http.Request, *matchedRoute) {
+	if route, ok := r.Context().Value(matchedRouteContextKey{}).(*matchedRoute); ok {
+		return r, route
+	}
+	route := &matchedRoute{}
+	return r.WithContext(context.WithValue(r.Context(), matchedRouteContextKey{}, route)), route
+}
+
+// RecordRoute tells Tracing, AccessLog and Metrics which route matched when
+// they wrap the router instead of being installed with Use. It must be
+// installed on the router with Use.
+func RecordRoute(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if route, ok := r.Context().Value(matchedRouteContextKey{}).(*matchedRoute); ok {
+			route.template = pathTemplate(r)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// routeTemplate is the path template of the matched route, or "" if no route
+// matched.
+func routeTemplate(r *http.Request, route *matchedRoute) string {
+	if route.template != "" {
+		return route.template
+	}
+	return pathTemplate(r)
+}
+
+// routeLabel is routeTemplate, or UnmatchedRoute if no route matched.
+func routeLabel(r *http.Request, route *matchedRoute) string {
+	if template := routeTemplate(r, route); template != "" {
+		return template
+	}
+	return UnmatchedRoute
+}
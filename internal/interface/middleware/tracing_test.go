@@ -0,0 +1,78 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"GO-API/internal/interface/middleware"
+	"GO-API/internal/pkg/tracing/tracingtest"
+)
+
+func TestTracingRecordsServerSpan(t *testing.T) {
+	exporter := tracingtest.Record(t)
+	previous := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTextMapPropagator(previous) })
+
+	router := mux.NewRouter()
+	router.Use(middleware.Tracing)
+	router.HandleFunc("/api/v1/payments/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if !trace.SpanFromContext(r.Context()).SpanContext().IsValid() {
+			t.Error("handler context carries no span")
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}).Methods(http.MethodGet)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/payments/pay_1", nil)
+	req.RemoteAddr = "203.0.113.7:51000"
+	req.Header.Set("User-Agent", "tracing-test")
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	span := tracingtest.Span(t, exporter, "GET /api/v1/payments/{id}")
+	if span.SpanKind != trace.SpanKindServer {
+		t.Errorf("span kind = %v, want server", span.SpanKind)
+	}
+	if got := span.SpanContext.TraceID().String(); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("trace ID = %s, want the caller's", got)
+	}
+	if got := span.Parent.SpanID().String(); got != "00f067aa0ba902b7" {
+		t.Errorf("parent span ID = %s, want the caller's", got)
+	}
+	if span.Status.Code == codes.Error {
+		t.Errorf("span status = error, want unset for a 4xx response")
+	}
+	tracingtest.AssertAttributes(t, span,
+		attribute.String("http.request.method", "GET"),
+		attribute.String("http.route", "/api/v1/payments/{id}"),
+		attribute.String("url.path", "/api/v1/payments/pay_1"),
+		attribute.String("client.address", "203.0.113.7"),
+		attribute.String("user_agent.original", "tracing-test"),
+		attribute.Int("http.response.status_code", http.StatusNotFound),
+	)
+}
+
+func TestTracingMarksServerErrors(t *testing.T) {
+	exporter := tracingtest.Record(t)
+
+	router := mux.NewRouter()
+	router.Use(middleware.Tracing)
+	router.HandleFunc("/fail", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	})
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/fail", nil))
+
+	span := tracingtest.Span(t, exporter, "GET /fail")
+	if span.Status.Code != codes.Error {
+		t.Errorf("span status = %v, want error", span.Status.Code)
+	}
+	tracingtest.AssertAttributes(t, span, attribute.Int("http.response.status_code", http.StatusBadGateway))
+}
@@ -0,0 +1,317 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+
+	"GO-API/internal/pkg/logger"
+)
+
+const (
+	DefaultJWKSRefreshInterval = 15 * time.Minute
+	// minJWKSRefetchInterval bounds how often an unknown kid may trigger an
+	// out-of-band refresh, so forged kids cannot be used to hammer the
+	// identity provider.
+	minJWKSRefetchInterval = time.Minute
+	jwksFetchTimeout       = 10 * time.Second
+)
+
+var ErrUnknownKeyID = errors.New("unknown key id")
+
+type jwk struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type jwkSet struct {
+	Keys []jwk `json:"keys"`
+}
+
+// KeySet holds the public keys of a JWKS document, loaded from a local file or
+// an HTTP endpoint, indexed by kid.
+type KeySet struct {
+	source string
+	// client starts a client span for each fetch and sends its traceparent
+	// to the identity provider.
+	client          *http.Client
+	refreshInterval time.Duration
+
+	mu          sync.RWMutex
+	keys        map[string]crypto.PublicKey
+	lastFetched time.Time
+	// lastKidRefresh is when an unknown kid last triggered a refresh, and
+	// kidRefresh is closed when that refresh finishes. Both are guarded by
+	// mu.
+	lastKidRefresh time.Time
+	kidRefresh     chan struct{}
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewKeySet loads the key set from source, which is either a file path or an
+// http(s) URL, and fails if the initial load fails.
+func NewKeySet(source string, refreshInterval time.Duration) (*KeySet, error) {
+	if refreshInterval <= 0 {
+		refreshInterval = DefaultJWKSRefreshInterval
+	}
+
+	ks := &KeySet{
+		source:          source,
+		client:          &http.Client{Timeout: jwksFetchTimeout, Transport: otelhttp.NewTransport(http.DefaultTransport)},
+		refreshInterval: refreshInterval,
+		keys:            make(map[string]crypto.PublicKey),
+	}
+
+	if err := ks.Refresh(context.Background()); err != nil {
+		return nil, err
+	}
+	return ks, nil
+}
+
+// Start refreshes the key set periodically in the background until Stop is
+// called. A failed refresh keeps serving the previously loaded keys.
+func (ks *KeySet) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	ks.cancel = cancel
+
+	ks.wg.Add(1)
+	go func() {
+		defer ks.wg.Done()
+
+		ticker := time.NewTicker(ks.refreshInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := ks.Refresh(ctx); err != nil {
+					logger.ErrorContext(ctx, "Failed to refresh JWKS", "source", ks.source, "error", err)
+				}
+			}
+		}
+	}()
+}
+
+func (ks *KeySet) Stop() {
+	if ks.cancel == nil {
+		return
+	}
+	ks.cancel()
+	ks.wg.Wait()
+}
+
+func (ks *KeySet) Refresh(ctx context.Context) error {
+	data, err := ks.fetch(ctx)
+	if err != nil {
+		return err
+	}
+
+	keys, err := parseJWKS(data)
+	if err != nil {
+		return err
+	}
+
+	ks.mu.Lock()
+	ks.keys = keys
+	ks.lastFetched = time.Now()
+	ks.mu.Unlock()
+
+	logger.InfoContext(ctx, "Loaded signing keys from JWKS", "count", len(keys), "source", ks.source)
+	return nil
+}
+
+// Key returns the public key for kid, refreshing the set if the kid is not
+// known yet (e.g. right after the identity provider rotated keys).
+func (ks *KeySet) Key(kid string) (crypto.PublicKey, error) {
+	ks.mu.RLock()
+	key, ok := ks.keys[kid]
+	ks.mu.RUnlock()
+	if ok {
+		return key, nil
+	}
+
+	ks.refreshForUnknownKid(kid)
+
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	if key, ok := ks.keys[kid]; ok {
+		return key, nil
+	}
+	return nil, ErrUnknownKeyID
+}
+
+// refreshForUnknownKid refreshes the set at most once per
+// minJWKSRefetchInterval, whether or not the refresh succeeds. Callers that
+// arrive while a refresh is in flight wait for it instead of starting another,
+// so a burst of tokens with unknown kids costs one fetch.
+func (ks *KeySet) refreshForUnknownKid(kid string) {
+	ks.mu.Lock()
+	if inflight := ks.kidRefresh; inflight != nil {
+		ks.mu.Unlock()
+		<-inflight
+		return
+	}
+	now := time.Now()
+	if now.Sub(ks.lastKidRefresh) < minJWKSRefetchInterval || now.Sub(ks.lastFetched) < minJWKSRefetchInterval {
+		ks.mu.Unlock()
+		return
+	}
+	ks.lastKidRefresh = now
+	done := make(chan struct{})
+	ks.kidRefresh = done
+	ks.mu.Unlock()
+
+	defer func() {
+		ks.mu.Lock()
+		ks.kidRefresh = nil
+		ks.mu.Unlock()
+		close(done)
+	}()
+
+	// The refresh is shared by every waiting caller, so it is not tied to
+	// the context of the request that triggered it.
+	if err := ks.Refresh(context.Background()); err != nil {
+		logger.Error("Failed to refresh JWKS for kid", "kid", kid, "error", err)
+	}
+}
+
+func (ks *KeySet) fetch(ctx context.Context) ([]byte, error) {
+	if !isURL(ks.source) {
+		data, err := os.ReadFile(ks.source)
+		if err != nil {
+			return nil, fmt.Errorf("error reading JWKS file: %w", err)
+		}
+		return data, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ks.source, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching JWKS: %w", err)
+	}
+	resp, err := ks.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error fetching JWKS: unexpected status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("error reading JWKS response: %w", err)
+	}
+	return data, nil
+}
+
+func isURL(source string) bool {
+	return strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://")
+}
+
+func parseJWKS(data []byte) (map[string]crypto.PublicKey, error) {
+	var set jwkSet
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("error parsing JWKS: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		key, err := k.publicKey()
+		if err != nil {
+			logger.Error("Skipping JWKS key", "kid", k.Kid, "error", err)
+			continue
+		}
+		keys[k.Kid] = key
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.New("JWKS contains no usable signing keys")
+	}
+	return keys, nil
+}
+
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid modulus: %w", err)
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exponent: %w", err)
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, errors.New("exponent too large")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("invalid x coordinate: %w", err)
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, fmt.Errorf("invalid y coordinate: %w", err)
+		}
+		key := &ecdsa.PublicKey{Curve: curve, X: x, Y: y}
+		if _, err := key.ECDH(); err != nil {
+			return nil, fmt.Errorf("invalid public key: %w", err)
+		}
+		return key, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,92 @@
+package auth_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"GO-API/internal/pkg/auth"
+	"GO-API/internal/pkg/tracing"
+	"GO-API/internal/pkg/tracing/tracingtest"
+)
+
+func TestKeySetRefreshPropagatesTraceContext(t *testing.T) {
+	exporter := tracingtest.Record(t)
+	previous := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTextMapPropagator(previous) })
+
+	traceparents := make(chan string, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparents <- r.Header.Get("traceparent")
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": []any{ecJWK(t, "key-1")}})
+	}))
+	defer server.Close()
+
+	keySet, err := auth.NewKeySet(server.URL, time.Hour)
+	if err != nil {
+		t.Fatalf("NewKeySet: %v", err)
+	}
+	if got := <-traceparents; got == "" {
+		t.Error("initial fetch sent no traceparent")
+	}
+
+	ctx, parent := tracing.Start(context.Background(), "refresh")
+	if err := keySet.Refresh(ctx); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	parent.End()
+
+	header := http.Header{}
+	header.Set("traceparent", <-traceparents)
+	remote := trace.SpanContextFromContext(
+		propagation.TraceContext{}.Extract(context.Background(), propagation.HeaderCarrier(header)))
+	if remote.TraceID() != parent.SpanContext().TraceID() {
+		t.Errorf("traceparent trace ID = %s, want %s", remote.TraceID(), parent.SpanContext().TraceID())
+	}
+
+	var client []string
+	for _, span := range exporter.GetSpans() {
+		if span.SpanKind == trace.SpanKindClient && span.SpanContext.TraceID() == parent.SpanContext().TraceID() {
+			client = append(client, span.SpanContext.SpanID().String())
+			if span.Parent.SpanID() != parent.SpanContext().SpanID() {
+				t.Errorf("client span parent = %s, want the refresh span", span.Parent.SpanID())
+			}
+		}
+	}
+	if len(client) != 1 {
+		t.Fatalf("got %d client spans for the refresh, want 1", len(client))
+	}
+	if remote.SpanID().String() != client[0] {
+		t.Errorf("traceparent span ID = %s, want the client span %s", remote.SpanID(), client[0])
+	}
+}
+
+func ecJWK(t *testing.T, kid string) map[string]string {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encode := func(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
+	return map[string]string{
+		"kid": kid,
+		"kty": "EC",
+		"use": "sig",
+		"crv": "P-256",
+		"x":   encode(key.X.FillBytes(make([]byte, 32))),
+		"y":   encode(key.Y.FillBytes(make([]byte, 32))),
+	}
+}
@@ -1,6 +1,7 @@
-package at
+package auth
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"time"
@@ -8,53 +9,186 @@ import (
 	"GO-API/internal/pkg/logger"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
 type Claims struct {
 	jwt.RegisteredClaims
-	UserID uint   `json:"user_id"`
-	ROle   string `json:"role"`
+	UserID     uint   `json:"user_id"`
+	Role       string `json:"role"`
+	MerchantID string `json:"merchant_id,omitempty"`
+	// Livemode selects live data only when explicitly true; tokens without
+	// the claim act on test data.
+	Livemode *bool `json:"livemode,omitempty"`
+}
+
+var (
+	ErrTokenRevoked     = errors.New("token has been revoked")
+	ErrIssuanceDisabled = errors.New("token issuance requires an HMAC secret")
+)
+
+var (
+	hmacMethods       = []string{"HS256", "HS384", "HS512"}
+	asymmetricMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}
+)
+
+type Config struct {
+	// HMACSecret enables HS256/384/512 tokens signed with a shared secret.
+	HMACSecret string
+	// KeySet enables RS*, PS* and ES* tokens verified with the key matching
+	// the token's kid header.
+	KeySet *KeySet
+	// Denylist, when set, is consulted for the jti of every valid token so
+	// that access tokens can be revoked before they expire.
+	Denylist Denylist
+
+	Issuer   string
+	Audience string
+	Leeway   time.Duration
+}
+
+// Denylist reports whether the token with the given jti has been revoked.
+type Denylist interface {
+	IsRevoked(ctx context.Context, jti string) (bool, error)
 }
 
 type JWTAuth struct {
 	secretKey []byte
+	keySet    *KeySet
+	denylist  Denylist
+	issuer    string
+	audience  string
+	parser    *jwt.Parser
 }
 
 func NewJWTAuth(secretKey string) *JWTAuth {
-	return &JWTAuth{
-		secretKey: []byte(secretKey),
-	}
+	j, _ := New(Config{HMACSecret: secretKey})
+	return j
 }
 
-func (j *JWTAuth) ValidateToken(tokenString string) (*Claims, error) {
-	logger.Info("Validating token")
+func New(config Config) (*JWTAuth, error) {
+	var methods []string
+	if config.HMACSecret != "" {
+		methods = append(methods, hmacMethods...)
+	}
+	if config.KeySet != nil {
+		methods = append(methods, asymmetricMethods...)
+	}
+	if len(methods) == 0 {
+		return nil, errors.New("either an HMAC secret or a key set is required")
+	}
 
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			logger.Error("Unexpected signing method: %v", token.Header["alg"])
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return j.secretKey, nil
-	})
+	// Tokens must expire, which matters for ones signed by a JWKS provider
+	// rather than by IssueToken, and must not claim to be issued in the
+	// future.
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods(methods),
+		jwt.WithLeeway(config.Leeway),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	}
+	if config.Issuer != "" {
+		options = append(options, jwt.WithIssuer(config.Issuer))
+	}
+	if config.Audience != "" {
+		options = append(options, jwt.WithAudience(config.Audience))
+	}
+
+	return &JWTAuth{
+		secretKey: []byte(config.HMACSecret),
+		keySet:    config.KeySet,
+		denylist:  config.Denylist,
+		issuer:    config.Issuer,
+		audience:  config.Audience,
+		parser:    jwt.NewParser(options...),
+	}, nil
+}
 
+func (j *JWTAuth) ValidateToken(ctx context.Context, tokenString string) (*Claims, error) {
+	logger.InfoContext(ctx, "Validating token")
+
+	token, err := j.parser.ParseWithClaims(tokenString, &Claims{}, j.keyFunc)
 	if err != nil {
-		logger.Error("Failed to parse token: %v", err)
+		logger.ErrorContext(ctx, "Failed to parse token", "error", err)
 		return nil, err
 	}
 
 	claims, ok := token.Claims.(*Claims)
 	if !ok || !token.Valid {
-		logger.Error("Invalid token claims")
+		logger.ErrorContext(ctx, "Invalid token claims")
 		return nil, errors.New("invalid token")
 	}
 
-	if claims.ExpiresAt != nil {
-		if claims.ExpiresAt.Time.Before(time.Now()) {
-			logger.Error("Token is expired")
-			return nil, errors.New("token is expired")
+	if j.denylist != nil && claims.ID != "" {
+		revoked, err := j.denylist.IsRevoked(ctx, claims.ID)
+		if err != nil {
+			logger.ErrorContext(ctx, "Failed to check token revocation", "error", err)
+			return nil, err
+		}
+		if revoked {
+			logger.InfoContext(ctx, "Rejected revoked token", "jti", claims.ID)
+			return nil, ErrTokenRevoked
 		}
 	}
 
-	logger.Info("Token validated sccessfully for user: %d", claims.UserID)
+	logger.InfoContext(ctx, "Token validated sccessfully", "user_id", claims.UserID)
 	return claims, nil
 }
+
+// IssueToken signs an HS256 access token for subject that expires after ttl
+// and acts on live or test data as livemode says. The token carries a random
+// jti so it can be revoked through the denylist.
+func (j *JWTAuth) IssueToken(subject, role, merchantID string, livemode bool, ttl time.Duration) (string, *Claims, error) {
+	if len(j.secretKey) == 0 {
+		return "", nil, ErrIssuanceDisabled
+	}
+
+	now := time.Now()
+	claims := &Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.New().String(),
+			Subject:   subject,
+			Issuer:    j.issuer,
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		},
+		Role:       role,
+		MerchantID: merchantID,
+		Livemode:   &livemode,
+	}
+	if j.audience != "" {
+		claims.Audience = jwt.ClaimStrings{j.audience}
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(j.secretKey)
+	if err != nil {
+		logger.Error("Failed to sign token", "error", err)
+		return "", nil, err
+	}
+	return token, claims, nil
+}
+
+func (j *JWTAuth) keyFunc(token *jwt.Token) (interface{}, error) {
+	switch token.Method.(type) {
+	case *jwt.SigningMethodHMAC:
+		if len(j.secretKey) == 0 {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return j.secretKey, nil
+
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS, *jwt.SigningMethodECDSA:
+		if j.keySet == nil {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		kid, _ := token.Header["kid"].(string)
+		if kid == "" {
+			return nil, errors.New("token has no kid header")
+		}
+		return j.keySet.Key(kid)
+
+	default:
+		logger.Error("Unexpected signing method", "alg", token.Header["alg"])
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+}
@@ -0,0 +1,54 @@
+package auth_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"GO-API/internal/pkg/auth"
+)
+
+const testSecret = "test-secret-that-is-long-enough-for-hs256"
+
+func TestValidateTokenRequiresExpiry(t *testing.T) {
+	jwtAuth, err := auth.New(auth.Config{HMACSecret: testSecret})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+
+	tests := []struct {
+		name    string
+		claims  jwt.RegisteredClaims
+		wantErr error
+	}{
+		{"no expiry", jwt.RegisteredClaims{Subject: "client", IssuedAt: jwt.NewNumericDate(now)}, jwt.ErrTokenRequiredClaimMissing},
+		{"issued in the future", jwt.RegisteredClaims{
+			Subject:   "client",
+			IssuedAt:  jwt.NewNumericDate(now.Add(time.Hour)),
+			ExpiresAt: jwt.NewNumericDate(now.Add(2 * time.Hour)),
+		}, jwt.ErrTokenUsedBeforeIssued},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, &auth.Claims{RegisteredClaims: tt.claims}).SignedString([]byte(testSecret))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := jwtAuth.ValidateToken(context.Background(), token); !errors.Is(err, tt.wantErr) {
+				t.Errorf("ValidateToken error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	token, _, err := jwtAuth.IssueToken("client", "admin", "merchant", true, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := jwtAuth.ValidateToken(context.Background(), token); err != nil {
+		t.Errorf("ValidateToken of an issued token: %v", err)
+	}
+}
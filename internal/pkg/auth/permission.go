@@ -0,0 +1,69 @@
+package auth
+
+import "strconv"
+
+type Permission string
+
+const (
+	PermissionPaymentsRead   Permission = "payments:read"
+	PermissionPaymentsWrite  Permission = "payments:write"
+	PermissionPaymentsRefund Permission = "payments:refund"
+	PermissionAdmin          Permission = "admin"
+)
+
+const (
+	RoleAdmin    = "admin"
+	RoleOperator = "operator"
+	RoleViewer   = "viewer"
+)
+
+// rolePermissions maps each role to the permissions it grants. The admin
+// permission implies every other permission.
+var rolePermissions = map[string][]Permission{
+	RoleAdmin:    {PermissionAdmin},
+	RoleOperator: {PermissionPaymentsRead, PermissionPaymentsWrite, PermissionPaymentsRefund},
+	RoleViewer:   {PermissionPaymentsRead},
+}
+
+var allPermissions = []Permission{
+	PermissionPaymentsRead,
+	PermissionPaymentsWrite,
+	PermissionPaymentsRefund,
+	PermissionAdmin,
+}
+
+func ValidPermission(permission Permission) bool {
+	for _, p := range allPermissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+func ValidRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+func HasPermission(role string, permission Permission) bool {
+	for _, granted := range rolePermissions[role] {
+		if granted == PermissionAdmin || granted == permission {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Claims) HasPermission(permission Permission) bool {
+	return HasPermission(c.Role, permission)
+}
+
+// Subject identifies the principal: the numeric user ID for user tokens, or
+// the sub claim for tokens issued to API clients.
+func (c *Claims) Subject() string {
+	if c.UserID == 0 && c.RegisteredClaims.Subject != "" {
+		return c.RegisteredClaims.Subject
+	}
+	return strconv.FormatUint(uint64(c.UserID), 10)
+}
@@ -0,0 +1,69 @@
+package auth
+
+import "strings"
+
+const (
+	AuthMethodJWT    = "jwt"
+	AuthMethodAPIKey = "api_key"
+)
+
+const apiKeySubjectPrefix = "api_key:"
+
+// Principal is the authenticated caller of a request, whether it presented a
+// JWT or a merchant API key.
+type Principal struct {
+	Subject    string
+	MerchantID string
+	Livemode   bool
+	AuthMethod string
+	// Role grants permissions to JWT principals.
+	Role string
+	// Scopes grant permissions to API key principals.
+	Scopes   []Permission
+	APIKeyID string
+}
+
+func NewJWTPrincipal(claims *Claims) *Principal {
+	return &Principal{
+		Subject:    claims.Subject(),
+		MerchantID: claims.MerchantID,
+		Livemode:   claims.Livemode != nil && *claims.Livemode,
+		AuthMethod: AuthMethodJWT,
+		Role:       claims.Role,
+	}
+}
+
+func NewAPIKeyPrincipal(keyID, merchantID string, livemode bool, scopes []Permission) *Principal {
+	return &Principal{
+		Subject:    apiKeySubjectPrefix + keyID,
+		MerchantID: merchantID,
+		Livemode:   livemode,
+		AuthMethod: AuthMethodAPIKey,
+		Scopes:     scopes,
+		APIKeyID:   keyID,
+	}
+}
+
+func (p *Principal) HasPermission(permission Permission) bool {
+	if p.AuthMethod != AuthMethodAPIKey {
+		return HasPermission(p.Role, permission)
+	}
+	for _, scope := range p.Scopes {
+		if scope == PermissionAdmin || scope == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// Grants describes what the principal is allowed to do, for log messages.
+func (p *Principal) Grants() string {
+	if p.AuthMethod != AuthMethodAPIKey {
+		return "role=" + p.Role
+	}
+	scopes := make([]string, len(p.Scopes))
+	for i, scope := range p.Scopes {
+		scopes[i] = string(scope)
+	}
+	return "scopes=" + strings.Join(scopes, ",")
+}
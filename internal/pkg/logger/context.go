@@ -0,0 +1,89 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+type fieldsContextKey struct{}
+
+// fields are attached to every record logged with a context carrying them.
+type fields struct {
+	requestID  string
+	merchantID string
+	userID     string
+	paymentID  string
+}
+
+func fieldsFromContext(ctx context.Context) fields {
+	if ctx == nil {
+		return fields{}
+	}
+	f, _ := ctx.Value(fieldsContextKey{}).(fields)
+	return f
+}
+
+func withFields(ctx context.Context, update func(*fields)) context.Context {
+	f := fieldsFromContext(ctx)
+	update(&f)
+	return context.WithValue(ctx, fieldsContextKey{}, f)
+}
+
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return withFields(ctx, func(f *fields) { f.requestID = id })
+}
+
+func WithMerchantID(ctx context.Context, id string) context.Context {
+	return withFields(ctx, func(f *fields) { f.merchantID = id })
+}
+
+func WithUserID(ctx context.Context, id string) context.Context {
+	return withFields(ctx, func(f *fields) { f.userID = id })
+}
+
+func WithPaymentID(ctx context.Context, id string) context.Context {
+	return withFields(ctx, func(f *fields) { f.paymentID = id })
+}
+
+// RequestID returns the request ID stored in ctx, if any.
+func RequestID(ctx context.Context) string {
+	return fieldsFromContext(ctx).requestID
+}
+
+// contextHandler adds the fields stored in the record's context, and the
+// trace and span IDs of the active span so logs can be joined with traces.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	f := fieldsFromContext(ctx)
+	if f.requestID != "" {
+		r.AddAttrs(slog.String("request_id", f.requestID))
+	}
+	if f.merchantID != "" {
+		r.AddAttrs(slog.String("merchant_id", f.merchantID))
+	}
+	if f.userID != "" {
+		r.AddAttrs(slog.String("user_id", f.userID))
+	}
+	if f.paymentID != "" {
+		r.AddAttrs(slog.String("payment_id", f.paymentID))
+	}
+	if ctx != nil {
+		if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+			r.AddAttrs(slog.String("trace_id", sc.TraceID().String()), slog.String("span_id", sc.SpanID().String()))
+		}
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
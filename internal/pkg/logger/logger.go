@@ -1,32 +1,117 @@
 package logger
 
 import (
-	"log"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
 	"os"
+	"strings"
 )
 
-type Logger struct {
-	logger *log.Logger
-}
+// Output formats accepted by Init.
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
 
-var defaultLogger *Logger
+var (
+	level         = new(slog.LevelVar)
+	defaultLogger *slog.Logger
+)
 
 func init() {
-	defaultLogger = &Logger{
-		logger: log.New(os.Stdout, "", log.LstdFlags),
+	// DEBUG=true predates LOG_LEVEL and is still honoured until Init is called.
+	if os.Getenv("DEBUG") == "true" {
+		level.Set(slog.LevelDebug)
 	}
+	defaultLogger = newLogger(os.Stdout, FormatText)
 }
 
-func Info(format string, v ...interface{}) {
-	defaultLogger.logger.Printf("[INFO] "+format, v...)
+// Init replaces the default logger with one writing format to w.
+func Init(w io.Writer, format string, lvl slog.Level) error {
+	if format != FormatJSON && format != FormatText {
+		return fmt.Errorf("unsupported log format %q", format)
+	}
+	level.Set(lvl)
+	defaultLogger = newLogger(w, format)
+	slog.SetDefault(defaultLogger)
+	return nil
 }
 
-func Error(format string, v ...interface{}) {
-	defaultLogger.logger.Printf("[ERROR] "+format, v...)
+func newLogger(w io.Writer, format string) *slog.Logger {
+	opts := &slog.HandlerOptions{Level: level, ReplaceAttr: redactAttr}
+	var handler slog.Handler
+	if format == FormatJSON {
+		handler = slog.NewJSONHandler(w, opts)
+	} else {
+		handler = slog.NewTextHandler(w, opts)
+	}
+	return slog.New(contextHandler{handler})
 }
 
-func Debug(format string, v ...interface{}) {
-	if os.Getenv("DEBUG") == "true" {
-		defaultLogger.logger.Printf("[DEBUG] "+format, v...)
+// SetLevel changes the minimum level of the default logger at runtime.
+func SetLevel(lvl slog.Level) {
+	level.Set(lvl)
+}
+
+func Level() slog.Level {
+	return level.Level()
+}
+
+// ParseLevel accepts debug, info, warn and error in any case.
+func ParseLevel(s string) (slog.Level, error) {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(strings.TrimSpace(s))); err != nil {
+		return 0, fmt.Errorf("unsupported log level %q", s)
 	}
+	return lvl, nil
+}
+
+// The functions below take a message followed by alternating keys and
+// values, as in log/slog. The Context variants also attach the fields stored
+// in ctx with WithRequestID, WithMerchantID, WithUserID and WithPaymentID.
+
+func Debug(msg string, args ...any) {
+	defaultLogger.Debug(msg, args...)
+}
+
+func Info(msg string, args ...any) {
+	defaultLogger.Info(msg, args...)
+}
+
+func Warn(msg string, args ...any) {
+	defaultLogger.Warn(msg, args...)
+}
+
+func Error(msg string, args ...any) {
+	defaultLogger.Error(msg, args...)
+}
+
+func DebugContext(ctx context.Context, msg string, args ...any) {
+	defaultLogger.DebugContext(ctx, msg, args...)
+}
+
+func InfoContext(ctx context.Context, msg string, args ...any) {
+	defaultLogger.InfoContext(ctx, msg, args...)
+}
+
+func WarnContext(ctx context.Context, msg string, args ...any) {
+	defaultLogger.WarnContext(ctx, msg, args...)
+}
+
+func ErrorContext(ctx context.Context, msg string, args ...any) {
+	defaultLogger.ErrorContext(ctx, msg, args...)
+}
+
+// LogContext logs at a level chosen at run time.
+func LogContext(ctx context.Context, level slog.Level, msg string, args ...any) {
+	defaultLogger.Log(ctx, level, msg, args...)
+}
+
+// Fatal logs at error level and exits the process. It is meant for startup
+// failures only.
+func Fatal(msg string, args ...any) {
+	defaultLogger.Error(msg, args...)
+	os.Exit(1)
 }
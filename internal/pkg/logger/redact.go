@@ -0,0 +1,375 @@
+package logger
+
+import (
+	"crypto/sha256"
+	"encoding"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Redaction says how a sensitive value is written to the log.
+type Redaction string
+
+const (
+	// RedactMask keeps the last four characters of strings and replaces the
+	// rest; other values are replaced entirely.
+	RedactMask Redaction = "mask"
+	// RedactHash replaces the value with a short SHA-256 digest, so records
+	// about the same customer can still be correlated.
+	RedactHash Redaction = "hash"
+	// RedactDrop omits the value.
+	RedactDrop Redaction = "drop"
+)
+
+// redactedPlaceholder replaces values that cannot be partially masked.
+const redactedPlaceholder = "[REDACTED]"
+
+// maxRedactDepth bounds the traversal of nested values.
+const maxRedactDepth = 8
+
+var (
+	sensitiveKeysMu sync.RWMutex
+	// sensitiveKeys applies to attribute keys, map keys and struct fields by
+	// their JSON name, whatever their type's tags say. Keys are stored in
+	// their normalized form; see sensitiveKey.
+	sensitiveKeys = normalizeKeys(map[string]Redaction{
+		"password":               RedactDrop,
+		"passwd":                 RedactDrop,
+		"secret":                 RedactDrop,
+		"token":                  RedactDrop,
+		"authorization":          RedactDrop,
+		"cookie":                 RedactDrop,
+		"api_key":                RedactDrop,
+		"private_key":            RedactDrop,
+		"card_number":            RedactMask,
+		"card_no":                RedactMask,
+		"pan":                    RedactMask,
+		"primary_account_number": RedactMask,
+		"cvc":                    RedactDrop,
+		"cvv":                    RedactDrop,
+	})
+)
+
+// RegisterSensitiveKey redacts every attribute, map entry or struct field
+// named key with redaction. Names match regardless of case and of the
+// separators between words, so registering "api_key" also covers "apiKey",
+// "API-Key" and compound names ending in it such as "x-api-key".
+func RegisterSensitiveKey(key string, redaction Redaction) {
+	sensitiveKeysMu.Lock()
+	defer sensitiveKeysMu.Unlock()
+	sensitiveKeys[strings.Join(keyWords(key), "")] = redaction
+}
+
+func normalizeKeys(keys map[string]Redaction) map[string]Redaction {
+	normalized := make(map[string]Redaction, len(keys))
+	for key, redaction := range keys {
+		normalized[strings.Join(keyWords(key), "")] = redaction
+	}
+	return normalized
+}
+
+// sensitiveKey looks key up by its trailing words, longest first, so that
+// "client_secret" and "webhookSecret" match "secret" while "japan" does not
+// match "pan".
+func sensitiveKey(key string) (Redaction, bool) {
+	words := keyWords(key)
+	sensitiveKeysMu.RLock()
+	defer sensitiveKeysMu.RUnlock()
+	for i := range words {
+		if redaction, ok := sensitiveKeys[strings.Join(words[i:], "")]; ok {
+			return redaction, true
+		}
+	}
+	return "", false
+}
+
+// keyWords splits key into lower case words at separators and at case
+// changes: "X-API-Key", "x_api_key" and "xApiKey" all give [x api key].
+func keyWords(key string) []string {
+	var words []string
+	start := -1
+	runes := []rune(key)
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if start >= 0 {
+				words = append(words, strings.ToLower(string(runes[start:i])))
+				start = -1
+			}
+			continue
+		}
+		if start >= 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				words = append(words, strings.ToLower(string(runes[start:i])))
+				start = i
+			}
+		}
+		if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		words = append(words, strings.ToLower(string(runes[start:])))
+	}
+	return words
+}
+
+// cardNumberPattern finds digit runs that could be card numbers, allowing
+// the usual space or dash grouping. Matches are masked only if they pass the
+// Luhn check, so order IDs and amounts are left alone.
+var cardNumberPattern = regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`)
+
+// redactAttr is installed as the handlers' ReplaceAttr. Struct fields may be
+// tagged `log:"mask"`, `log:"hash"` or `log:"drop"` (or `log:"-"`); fields
+// tagged `json:"-"` are dropped as well. Card numbers are masked in every
+// string, including the message.
+func redactAttr(groups []string, a slog.Attr) slog.Attr {
+	if redaction, ok := sensitiveKey(a.Key); ok {
+		return redactedAttr(a.Key, a.Value.Resolve().Any(), redaction)
+	}
+
+	v := a.Value.Resolve()
+	switch v.Kind() {
+	case slog.KindString:
+		return slog.String(a.Key, maskCardNumbers(v.String()))
+	case slog.KindAny:
+		return slog.Any(a.Key, redactValue(v.Any()))
+	}
+	return a
+}
+
+func redactedAttr(key string, value any, redaction Redaction) slog.Attr {
+	if redaction == RedactDrop {
+		return slog.Attr{}
+	}
+	return slog.Any(key, applyRedaction(reflect.ValueOf(value), redaction))
+}
+
+// redactValue returns a copy of value that is safe to log. Structs and maps
+// are rebuilt as maps keyed by JSON field name.
+func redactValue(value any) any {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case error:
+		return maskCardNumbers(v.Error())
+	case fmt.Stringer:
+		if _, ok := value.(encoding.TextMarshaler); ok {
+			return maskCardNumbers(v.String())
+		}
+	}
+	return redactReflect(reflect.ValueOf(value), 0)
+}
+
+func redactReflect(v reflect.Value, depth int) any {
+	if !v.IsValid() {
+		return nil
+	}
+	if depth > maxRedactDepth {
+		return redactedPlaceholder
+	}
+
+	if v.CanInterface() {
+		if m, ok := v.Interface().(encoding.TextMarshaler); ok {
+			if v.Kind() == reflect.Pointer && v.IsNil() {
+				return nil
+			}
+			text, err := m.MarshalText()
+			if err != nil {
+				return redactedPlaceholder
+			}
+			return maskCardNumbers(string(text))
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return redactReflect(v.Elem(), depth+1)
+	case reflect.String:
+		return maskCardNumbers(v.String())
+	case reflect.Struct:
+		return redactStruct(v, depth)
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return redactedPlaceholder
+		}
+		out := make(map[string]any, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			key := iter.Key().String()
+			if redaction, ok := sensitiveKey(key); ok {
+				if redaction != RedactDrop {
+					out[key] = applyRedaction(iter.Value(), redaction)
+				}
+				continue
+			}
+			out[key] = redactReflect(iter.Value(), depth+1)
+		}
+		return out
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return redactedPlaceholder
+		}
+		out := make([]any, v.Len())
+		for i := range out {
+			out[i] = redactReflect(v.Index(i), depth+1)
+		}
+		return out
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return redactedPlaceholder
+	}
+
+	if v.CanInterface() {
+		return v.Interface()
+	}
+	return redactedPlaceholder
+}
+
+func redactStruct(v reflect.Value, depth int) map[string]any {
+	t := v.Type()
+	out := make(map[string]any, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, drop := jsonFieldName(field)
+		if drop {
+			continue
+		}
+
+		// Embedded structs without a JSON name are flattened, as in
+		// encoding/json.
+		if field.Anonymous && field.Tag.Get("json") == "" {
+			embedded := v.Field(i)
+			if embedded.Kind() == reflect.Pointer {
+				if embedded.IsNil() {
+					continue
+				}
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				for k, value := range redactStruct(embedded, depth+1) {
+					if _, ok := out[k]; !ok {
+						out[k] = value
+					}
+				}
+				continue
+			}
+		}
+
+		redaction, tagged := fieldRedaction(field)
+		if !tagged {
+			redaction, tagged = sensitiveKey(name)
+		}
+		switch {
+		case !tagged:
+			out[name] = redactReflect(v.Field(i), depth+1)
+		case redaction != RedactDrop:
+			out[name] = applyRedaction(v.Field(i), redaction)
+		}
+	}
+	return out
+}
+
+func jsonFieldName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", true
+	}
+	name, _, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = field.Name
+	}
+	return name, false
+}
+
+func fieldRedaction(field reflect.StructField) (Redaction, bool) {
+	switch tag := field.Tag.Get("log"); tag {
+	case "":
+		return "", false
+	case "-":
+		return RedactDrop, true
+	default:
+		return Redaction(tag), true
+	}
+}
+
+func applyRedaction(v reflect.Value, redaction Redaction) any {
+	for v.IsValid() && (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return nil
+	}
+	if v.Kind() == reflect.String && v.Len() == 0 {
+		return ""
+	}
+
+	switch redaction {
+	case RedactHash:
+		sum := sha256.Sum256([]byte(fmt.Sprint(v.Interface())))
+		return "sha256:" + hex.EncodeToString(sum[:6])
+	case RedactMask:
+		if v.Kind() == reflect.String {
+			return mask(v.String())
+		}
+	}
+	return redactedPlaceholder
+}
+
+// mask keeps the last four characters of s when it is long enough for them
+// not to give the value away.
+func mask(s string) string {
+	if len(s) <= 8 {
+		return strings.Repeat("*", len(s))
+	}
+	return strings.Repeat("*", len(s)-4) + s[len(s)-4:]
+}
+
+func maskCardNumbers(s string) string {
+	if !strings.ContainsAny(s, "0123456789") {
+		return s
+	}
+	return cardNumberPattern.ReplaceAllStringFunc(s, func(match string) string {
+		digits := strings.NewReplacer(" ", "", "-", "").Replace(match)
+		if !luhnValid(digits) {
+			return match
+		}
+		return mask(digits)
+	})
+}
+
+func luhnValid(digits string) bool {
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
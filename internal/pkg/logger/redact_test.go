@@ -0,0 +1,201 @@
+package logger_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+
+	"GO-API/internal/domain/model"
+	"GO-API/internal/interface/handler"
+	"GO-API/internal/pkg/logger"
+)
+
+// capture logs through the default logger in format and returns the output.
+func capture(t *testing.T, format string, log func()) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := logger.Init(&buf, format, slog.LevelDebug); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { logger.Init(os.Stdout, logger.FormatText, slog.LevelInfo) })
+	log()
+	return buf.String()
+}
+
+func captureJSON(t *testing.T, log func()) map[string]any {
+	t.Helper()
+	out := capture(t, logger.FormatJSON, log)
+	var record map[string]any
+	if err := json.Unmarshal([]byte(out), &record); err != nil {
+		t.Fatalf("invalid JSON record %q: %v", out, err)
+	}
+	return record
+}
+
+func field(t *testing.T, record map[string]any, path ...string) (any, bool) {
+	t.Helper()
+	var v any = record
+	for _, key := range path {
+		m, ok := v.(map[string]any)
+		if !ok {
+			t.Fatalf("%s is not an object in %v", key, record)
+		}
+		if v, ok = m[key]; !ok {
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+func assertField(t *testing.T, record map[string]any, want any, path ...string) {
+	t.Helper()
+	got, ok := field(t, record, path...)
+	if !ok {
+		t.Errorf("%s missing from %v", strings.Join(path, "."), record)
+		return
+	}
+	if got != want {
+		t.Errorf("%s = %v, want %v", strings.Join(path, "."), got, want)
+	}
+}
+
+func assertAbsent(t *testing.T, record map[string]any, path ...string) {
+	t.Helper()
+	if got, ok := field(t, record, path...); ok {
+		t.Errorf("%s = %v, want it dropped", strings.Join(path, "."), got)
+	}
+}
+
+func assertHashed(t *testing.T, record map[string]any, path ...string) {
+	t.Helper()
+	got, _ := field(t, record, path...)
+	if s, ok := got.(string); !ok || !strings.HasPrefix(s, "sha256:") {
+		t.Errorf("%s = %v, want a sha256 digest", strings.Join(path, "."), got)
+	}
+}
+
+func assertTextOmits(t *testing.T, log func(), secrets ...string) {
+	t.Helper()
+	out := capture(t, logger.FormatText, log)
+	for _, secret := range secrets {
+		if strings.Contains(out, secret) {
+			t.Errorf("text output contains %q: %s", secret, out)
+		}
+	}
+}
+
+func TestRedactPayment(t *testing.T) {
+	payment := &model.Payment{
+		ID:          "pay_1",
+		Amount:      1000,
+		Description: "gift for alice",
+		CustomerID:  "cus_123",
+		Metadata:    model.PaymentMetadata{OrderID: "4242424242424242"},
+	}
+	log := func() { logger.Info("Created payment", "payment", payment) }
+
+	record := captureJSON(t, log)
+	assertField(t, record, "pay_1", "payment", "id")
+	assertAbsent(t, record, "payment", "description")
+	assertHashed(t, record, "payment", "customer_id")
+	assertField(t, record, "************4242", "payment", "metadata", "order_id")
+
+	assertTextOmits(t, log, "gift for alice", "cus_123", "4242424242424242")
+}
+
+func TestRedactRequestBodies(t *testing.T) {
+	req := handler.CreatePaymentRequest{
+		Amount:      500,
+		Description: "gift for alice",
+		CustomerID:  "cus_123",
+		OrderID:     "order-1",
+	}
+	key := handler.APIKeyWithSecret{
+		APIKey: &model.APIKey{ID: "key_1", KeyHash: "hash"},
+		Secret: "sk_live_0123456789",
+	}
+	log := func() { logger.Info("Request", "request", req, "api_key_created", key) }
+
+	record := captureJSON(t, log)
+	assertField(t, record, "order-1", "request", "order_id")
+	assertAbsent(t, record, "request", "description")
+	assertHashed(t, record, "request", "customer_id")
+	assertField(t, record, "key_1", "api_key_created", "id")
+	assertAbsent(t, record, "api_key_created", "secret")
+	assertAbsent(t, record, "api_key_created", "KeyHash")
+
+	assertTextOmits(t, log, "gift for alice", "cus_123", "sk_live_0123456789")
+}
+
+func TestRedactMapKeyVariants(t *testing.T) {
+	body := map[string]any{
+		"cardNumber":    "4111111111111111",
+		"pan":           "5555555555554444",
+		"x-api-key":     "key_0123456789",
+		"webhookSecret": "whsec_0123456789",
+		"japan":         "tokyo",
+		"nested":        map[string]string{"Password": "hunter2", "note": "ok"},
+	}
+	log := func() { logger.Info("Callback body", "body", body) }
+
+	record := captureJSON(t, log)
+	assertField(t, record, "************1111", "body", "cardNumber")
+	assertField(t, record, "************4444", "body", "pan")
+	assertAbsent(t, record, "body", "x-api-key")
+	assertAbsent(t, record, "body", "webhookSecret")
+	assertField(t, record, "tokyo", "body", "japan")
+	assertAbsent(t, record, "body", "nested", "Password")
+	assertField(t, record, "ok", "body", "nested", "note")
+
+	assertTextOmits(t, log, "4111111111111111", "5555555555554444", "key_0123456789", "whsec_0123456789", "hunter2")
+}
+
+func TestRedactAttributeKeys(t *testing.T) {
+	log := func() {
+		logger.Info("Login",
+			"password", "hunter2",
+			"Authorization", "Bearer abc.def",
+			"X-Api-Key", "key_0123456789",
+			"refreshToken", "rt_0123456789",
+			"client_secret", "cs_0123456789",
+			"card_number", "4111111111111111",
+			"cvv", "123",
+			"user", "alice")
+	}
+
+	record := captureJSON(t, log)
+	for _, key := range []string{"password", "Authorization", "X-Api-Key", "refreshToken", "client_secret", "cvv"} {
+		assertAbsent(t, record, key)
+	}
+	assertField(t, record, "************1111", "card_number")
+	assertField(t, record, "alice", "user")
+
+	assertTextOmits(t, log, "hunter2", "abc.def", "key_0123456789", "rt_0123456789", "cs_0123456789", "4111111111111111")
+}
+
+func TestRedactRegisteredKey(t *testing.T) {
+	logger.RegisterSensitiveKey("merchant_pin", logger.RedactHash)
+	log := func() { logger.Info("Verified", "merchantPin", "98765") }
+
+	record := captureJSON(t, log)
+	assertHashed(t, record, "merchantPin")
+
+	assertTextOmits(t, log, "98765")
+}
+
+func TestRedactCardNumbersInErrorsAndMessages(t *testing.T) {
+	err := errors.New("charge declined for card 4111 1111 1111 1111")
+	log := func() {
+		logger.Error("Card 4242-4242-4242-4242 declined for order 1234567890123456", "error", err)
+	}
+
+	record := captureJSON(t, log)
+	assertField(t, record, "Card ************4242 declined for order 1234567890123456", "msg")
+	assertField(t, record, "charge declined for card ************1111", "error")
+
+	assertTextOmits(t, log, "4111 1111 1111 1111", "4242-4242-4242-4242")
+}
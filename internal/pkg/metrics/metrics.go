@@ -0,0 +1,117 @@
+// Package metrics defines the application's Prometheus metrics and serves
+// them in the Prometheus exposition format.
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "goapi"
+
+// Registry holds every application metric. A dedicated registry keeps
+// metrics registered by libraries out of the exposition.
+var Registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by method, route template and status code.",
+	}, []string{"method", "route", "status"})
+
+	httpRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by method, route template and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	payments = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "payments_total",
+		Help:      "Payments entering each status, by currency and payment method.",
+	}, []string{"status", "currency", "method"})
+
+	paymentAmount = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "payment_amount_total",
+		Help:      "Sum of the amounts, in minor units, of payments entering each status.",
+	}, []string{"status", "currency"})
+
+	processorDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "processor_call_duration_seconds",
+		Help:      "Payment processor call latency by processor and operation.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"processor", "operation"})
+
+	panics = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_panics_total",
+		Help:      "Panics recovered while serving HTTP requests.",
+	})
+
+	processorErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "processor_call_errors_total",
+		Help:      "Failed payment processor calls by processor and operation.",
+	}, []string{"processor", "operation"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests,
+		httpRequestDuration,
+		payments,
+		paymentAmount,
+		processorDuration,
+		processorErrors,
+		panics,
+	)
+}
+
+// RegisterDB exports the connection pool statistics of db.
+func RegisterDB(db *sql.DB, name string) {
+	Registry.MustRegister(collectors.NewDBStatsCollector(db, name))
+}
+
+// Handler serves the registry in the Prometheus exposition format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// ObserveHTTPRequest records a completed HTTP request. route must be a path
+// template, never a raw path, to keep the number of series bounded.
+func ObserveHTTPRequest(method, route string, status int, duration time.Duration) {
+	code := strconv.Itoa(status)
+	httpRequests.WithLabelValues(method, route, code).Inc()
+	httpRequestDuration.WithLabelValues(method, route, code).Observe(duration.Seconds())
+}
+
+// ObservePayment records a payment entering status.
+func ObservePayment(status, currency, method string, amount int64) {
+	payments.WithLabelValues(status, currency, method).Inc()
+	paymentAmount.WithLabelValues(status, currency).Add(float64(amount))
+}
+
+// ObserveProcessorCall records a call to a payment processor.
+func ObserveProcessorCall(processor, operation string, duration time.Duration, err error) {
+	processorDuration.WithLabelValues(processor, operation).Observe(duration.Seconds())
+	if err != nil {
+		processorErrors.WithLabelValues(processor, operation).Inc()
+	}
+}
+
+// ObservePanic counts a panic recovered while serving a request.
+func ObservePanic() {
+	panics.Inc()
+}
@@ -0,0 +1,59 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepInterval bounds how often idle buckets are dropped from memory.
+const sweepInterval = time.Minute
+
+type memoryEntry struct {
+	bucket Bucket
+	period time.Duration
+}
+
+// MemoryStore keeps buckets in process memory. Limits are enforced per
+// replica; use a shared store when running more than one.
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*memoryEntry
+	lastSweep time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: make(map[string]*memoryEntry),
+	}
+}
+
+func (s *MemoryStore) Take(_ context.Context, key string, limit Limit, now time.Time) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep(now)
+
+	entry, ok := s.buckets[key]
+	if !ok {
+		entry = &memoryEntry{}
+		s.buckets[key] = entry
+	}
+	entry.period = limit.Period
+	return limit.Take(&entry.bucket, now), nil
+}
+
+// sweep drops buckets that have been idle for a whole period, since they
+// would be full again and are equivalent to a missing bucket.
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+
+	for key, entry := range s.buckets {
+		if now.Sub(entry.bucket.UpdatedAt) > entry.period {
+			delete(s.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package tracing_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/protobuf/proto"
+
+	"GO-API/internal/pkg/tracing"
+)
+
+// TestInitExportsOverOTLP runs the OTLP exporter against a stand-in collector
+// and checks that ended spans arrive with the service name.
+func TestInitExportsOverOTLP(t *testing.T) {
+	requests := make(chan *coltracepb.ExportTraceServiceRequest, 1)
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v1/traces" {
+			t.Errorf("collector got %s %s, want POST /v1/traces", r.Method, r.URL.Path)
+			http.NotFound(w, r)
+			return
+		}
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("reading export: %v", err)
+			return
+		}
+		var req coltracepb.ExportTraceServiceRequest
+		if err := proto.Unmarshal(body, &req); err != nil {
+			t.Errorf("decoding export: %v", err)
+			return
+		}
+		requests <- &req
+		w.Header().Set("Content-Type", "application/x-protobuf")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer collector.Close()
+
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", collector.URL)
+	previousProvider := otel.GetTracerProvider()
+	previousPropagator := otel.GetTextMapPropagator()
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+
+	shutdown, err := tracing.Init(context.Background(), tracing.Config{
+		Exporter:    tracing.ExporterOTLP,
+		ServiceName: "go-api-test",
+		SampleRatio: 1,
+	})
+	if err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+
+	_, span := tracing.Start(context.Background(), "otlp-test")
+	span.End()
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+
+	var req *coltracepb.ExportTraceServiceRequest
+	select {
+	case req = <-requests:
+	default:
+		t.Fatal("collector received no export")
+	}
+
+	var names []string
+	for _, resourceSpans := range req.GetResourceSpans() {
+		var service string
+		for _, attr := range resourceSpans.GetResource().GetAttributes() {
+			if attr.GetKey() == "service.name" {
+				service = attr.GetValue().GetStringValue()
+			}
+		}
+		for _, scopeSpans := range resourceSpans.GetScopeSpans() {
+			for _, s := range scopeSpans.GetSpans() {
+				names = append(names, s.GetName())
+				if service != "go-api-test" {
+					t.Errorf("span %q service.name = %q, want go-api-test", s.GetName(), service)
+				}
+			}
+		}
+	}
+	if len(names) != 1 || names[0] != "otlp-test" {
+		t.Errorf("exported spans = %v, want [otlp-test]", names)
+	}
+}
@@ -0,0 +1,68 @@
+// Package tracingtest records the spans started during a test.
+package tracingtest
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// Record installs a global tracer provider that exports every span to the
+// returned in-memory exporter as soon as it ends. The previous provider is
+// restored when the test finishes.
+func Record(t testing.TB) *tracetest.InMemoryExporter {
+	t.Helper()
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+		_ = provider.Shutdown(context.Background())
+	})
+	return exporter
+}
+
+// Span returns the only ended span named name, failing the test if there
+// is not exactly one.
+func Span(t testing.TB, exporter *tracetest.InMemoryExporter, name string) tracetest.SpanStub {
+	t.Helper()
+	var found []tracetest.SpanStub
+	for _, span := range exporter.GetSpans() {
+		if span.Name == name {
+			found = append(found, span)
+		}
+	}
+	if len(found) != 1 {
+		var names []string
+		for _, span := range exporter.GetSpans() {
+			names = append(names, span.Name)
+		}
+		t.Fatalf("got %d spans named %q, want 1; recorded spans: %v", len(found), name, names)
+	}
+	return found[0]
+}
+
+// AssertAttributes fails the test unless span has every attribute in want.
+func AssertAttributes(t testing.TB, span tracetest.SpanStub, want ...attribute.KeyValue) {
+	t.Helper()
+	got := make(map[attribute.Key]attribute.Value, len(span.Attributes))
+	for _, attr := range span.Attributes {
+		got[attr.Key] = attr.Value
+	}
+	for _, attr := range want {
+		value, ok := got[attr.Key]
+		if !ok {
+			t.Errorf("span %q has no attribute %s", span.Name, attr.Key)
+			continue
+		}
+		if value != attr.Value {
+			t.Errorf("span %q attribute %s = %s, want %s", span.Name, attr.Key, value.Emit(), attr.Value.Emit())
+		}
+	}
+}
@@ -0,0 +1,319 @@
+package usecase
+
+import (
+	"context"
+	"net/netip"
+	"time"
+
+	"github.com/google/uuid"
+
+	"GO-API/internal/domain/model"
+	"GO-API/internal/gateway"
+	"GO-API/internal/pkg/auth"
+	"GO-API/internal/pkg/logger"
+)
+
+const (
+	MaxAPIKeyNameLength    = 100
+	MaxAPIKeyAllowedIPs    = 50
+	MaxAPIKeyRotationGrace = 7 * 24 * time.Hour
+
+	// apiKeyLastUsedInterval bounds how often a key's last-used timestamp is
+	// written, so busy integrations do not update the row on every request.
+	apiKeyLastUsedInterval = time.Minute
+)
+
+const (
+	AuditResourceAPIKey = "api_key"
+
+	AuditActionAPIKeyCreate = "api_key.create"
+	AuditActionAPIKeyRotate = "api_key.rotate"
+	AuditActionAPIKeyRevoke = "api_key.revoke"
+)
+
+var errInvalidAPIKey = model.NewUnauthorizedError("invalid api key")
+
+type APIKeyUseCase struct {
+	repo  gateway.APIKeyRepository
+	audit *AuditUseCase
+}
+
+func NewAPIKeyUseCase(repo gateway.APIKeyRepository, audit *AuditUseCase) *APIKeyUseCase {
+	return &APIKeyUseCase{
+		repo:  repo,
+		audit: audit,
+	}
+}
+
+type CreateAPIKeyInput struct {
+	Name       string
+	Livemode   bool
+	Scopes     []string
+	AllowedIPs []string
+}
+
+// CreateKey generates a new API key and returns it together with the secret,
+// which is only shown once.
+func (uc *APIKeyUseCase) CreateKey(ctx context.Context, input CreateAPIKeyInput) (*model.APIKey, string, error) {
+	logger.InfoContext(ctx, "Creating API key", "name", input.Name, "livemode", input.Livemode)
+
+	if input.Name == "" {
+		return nil, "", model.NewValidationError("name is required")
+	}
+	if len(input.Name) > MaxAPIKeyNameLength {
+		return nil, "", model.NewValidationError("name is too long")
+	}
+	if input.Livemode && !livemode(ctx) {
+		return nil, "", model.NewForbiddenError("live api keys cannot be created in test mode")
+	}
+
+	scopes, err := validateScopes(input.Scopes)
+	if err != nil {
+		return nil, "", err
+	}
+	allowedIPs, err := validateAllowedIPs(input.AllowedIPs)
+	if err != nil {
+		return nil, "", err
+	}
+
+	key := &model.APIKey{
+		ID:         uuid.New().String(),
+		MerchantID: merchantID(ctx),
+		Name:       input.Name,
+		Livemode:   input.Livemode,
+		Scopes:     scopes,
+		AllowedIPs: allowedIPs,
+		CreatedAt:  time.Now(),
+	}
+
+	secret, err := uc.create(ctx, key)
+	if err != nil {
+		return nil, "", err
+	}
+
+	uc.audit.Record(ctx, AuditActionAPIKeyCreate, AuditResourceAPIKey, key.ID)
+	return key, secret, nil
+}
+
+func (uc *APIKeyUseCase) GetKey(ctx context.Context, id string) (*model.APIKey, error) {
+	key, err := uc.repo.FindByID(ctx, merchantID(ctx), id)
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to find API key", "error", err)
+		return nil, err
+	}
+	return key, nil
+}
+
+func (uc *APIKeyUseCase) ListKeys(ctx context.Context, limit, offset int) ([]*model.APIKey, error) {
+	logger.InfoContext(ctx, "Listing API keys", "limit", limit, "offset", offset)
+
+	if limit <= 0 {
+		limit = 10
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	keys, err := uc.repo.List(ctx, merchantID(ctx), limit, offset)
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to list API keys", "error", err)
+		return nil, err
+	}
+	return keys, nil
+}
+
+// RotateKey replaces an active key with a new secret carrying the same name,
+// mode, scopes and allowlist. The old key keeps working for grace, so that
+// deployments can switch over, and is revoked immediately when grace is zero.
+func (uc *APIKeyUseCase) RotateKey(ctx context.Context, id string, grace time.Duration) (*model.APIKey, string, error) {
+	logger.InfoContext(ctx, "Rotating API key", "api_key_id", id, "grace", grace)
+
+	if grace < 0 || grace > MaxAPIKeyRotationGrace {
+		return nil, "", model.NewValidationError("grace period must be between 0 and 7 days")
+	}
+
+	old, err := uc.repo.FindByID(ctx, merchantID(ctx), id)
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to find API key", "error", err)
+		return nil, "", err
+	}
+
+	now := time.Now()
+	if !old.Active(now) {
+		return nil, "", model.NewValidationError("api key is revoked or expired")
+	}
+
+	key := &model.APIKey{
+		ID:         uuid.New().String(),
+		MerchantID: old.MerchantID,
+		Name:       old.Name,
+		Livemode:   old.Livemode,
+		Scopes:     old.Scopes,
+		AllowedIPs: old.AllowedIPs,
+		CreatedAt:  now,
+	}
+
+	secret, err := uc.create(ctx, key)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if grace == 0 {
+		old.RevokedAt = &now
+	} else {
+		expiresAt := now.Add(grace)
+		old.ExpiresAt = &expiresAt
+	}
+	if err := uc.repo.Update(ctx, old); err != nil {
+		logger.ErrorContext(ctx, "Failed to retire rotated API key", "api_key_id", old.ID, "error", err)
+		return nil, "", err
+	}
+
+	uc.audit.Record(ctx, AuditActionAPIKeyRotate, AuditResourceAPIKey, old.ID)
+	return key, secret, nil
+}
+
+func (uc *APIKeyUseCase) RevokeKey(ctx context.Context, id string) (*model.APIKey, error) {
+	logger.InfoContext(ctx, "Revoking API key", "api_key_id", id)
+
+	key, err := uc.repo.FindByID(ctx, merchantID(ctx), id)
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to find API key", "error", err)
+		return nil, err
+	}
+
+	if key.RevokedAt != nil {
+		return key, nil
+	}
+
+	now := time.Now()
+	key.RevokedAt = &now
+	if err := uc.repo.Update(ctx, key); err != nil {
+		logger.ErrorContext(ctx, "Failed to revoke API key", "error", err)
+		return nil, err
+	}
+
+	uc.audit.Record(ctx, AuditActionAPIKeyRevoke, AuditResourceAPIKey, key.ID)
+	return key, nil
+}
+
+// AuthenticateAPIKey resolves a presented secret key to a principal. remoteIP
+// is checked against the key's allowlist when one is configured.
+func (uc *APIKeyUseCase) AuthenticateAPIKey(ctx context.Context, secret string, remoteIP string) (*auth.Principal, error) {
+	key, err := uc.repo.FindByHash(ctx, auth.HashSecret(secret))
+	if err != nil {
+		if isNotFound(err) {
+			return nil, errInvalidAPIKey
+		}
+		logger.ErrorContext(ctx, "Failed to find API key", "error", err)
+		return nil, err
+	}
+
+	now := time.Now()
+	if !key.Active(now) {
+		logger.InfoContext(ctx, "Rejected inactive API key", "api_key_id", key.ID)
+		return nil, errInvalidAPIKey
+	}
+
+	if !ipAllowed(key.AllowedIPs, remoteIP) {
+		logger.InfoContext(ctx, "Rejected API key from disallowed IP", "api_key_id", key.ID, "remote_ip", remoteIP)
+		return nil, model.NewForbiddenError("request IP is not allowed for this api key")
+	}
+
+	if err := uc.repo.TouchLastUsed(ctx, key.ID, now, apiKeyLastUsedInterval); err != nil {
+		logger.ErrorContext(ctx, "Failed to record API key use", "error", err)
+	}
+
+	scopes := make([]auth.Permission, len(key.Scopes))
+	for i, scope := range key.Scopes {
+		scopes[i] = auth.Permission(scope)
+	}
+	return auth.NewAPIKeyPrincipal(key.ID, key.MerchantID, key.Livemode, scopes), nil
+}
+
+func (uc *APIKeyUseCase) create(ctx context.Context, key *model.APIKey) (string, error) {
+	random, err := auth.GenerateSecret()
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to generate API key", "error", err)
+		return "", model.NewInternalError(err)
+	}
+
+	secret := key.Prefix() + random
+	key.KeyHash = auth.HashSecret(secret)
+	key.Last4 = secret[len(secret)-4:]
+
+	if err := uc.repo.Create(ctx, key); err != nil {
+		logger.ErrorContext(ctx, "Failed to save API key", "error", err)
+		return "", err
+	}
+	return secret, nil
+}
+
+func validateScopes(scopes []string) ([]string, error) {
+	if len(scopes) == 0 {
+		return nil, model.NewValidationError("at least one scope is required")
+	}
+
+	seen := make(map[string]bool, len(scopes))
+	result := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		if !auth.ValidPermission(auth.Permission(scope)) {
+			return nil, model.NewValidationError("invalid scope: " + scope)
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			result = append(result, scope)
+		}
+	}
+	return result, nil
+}
+
+// validateAllowedIPs accepts single addresses and CIDR ranges and normalises
+// them to prefixes. An empty allowlist allows every address.
+func validateAllowedIPs(entries []string) ([]string, error) {
+	if len(entries) > MaxAPIKeyAllowedIPs {
+		return nil, model.NewValidationError("too many allowed_ips")
+	}
+
+	result := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		prefix, err := parseIPPrefix(entry)
+		if err != nil {
+			return nil, model.NewValidationError("invalid allowed_ips entry: " + entry)
+		}
+		result = append(result, prefix.String())
+	}
+	return result, nil
+}
+
+func parseIPPrefix(entry string) (netip.Prefix, error) {
+	if prefix, err := netip.ParsePrefix(entry); err == nil {
+		return prefix.Masked(), nil
+	}
+	addr, err := netip.ParseAddr(entry)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	addr = addr.Unmap()
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+func ipAllowed(allowed []string, remoteIP string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+
+	addr, err := netip.ParseAddr(remoteIP)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+
+	for _, entry := range allowed {
+		prefix, err := netip.ParsePrefix(entry)
+		if err == nil && prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,68 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"GO-API/internal/domain/model"
+	"GO-API/internal/gateway"
+	"GO-API/internal/pkg/auth"
+	"GO-API/internal/pkg/logger"
+)
+
+type AuditUseCase struct {
+	repo gateway.AuditRepository
+}
+
+func NewAuditUseCase(repo gateway.AuditRepository) *AuditUseCase {
+	return &AuditUseCase{
+		repo: repo,
+	}
+}
+
+// Record stores an audit entry for the user acting in ctx. Failures are
+// logged and never abort the audited operation.
+func (uc *AuditUseCase) Record(ctx context.Context, action, resourceType, resourceID string) {
+	entry := &model.AuditEntry{
+		ID:           uuid.New().String(),
+		MerchantID:   merchantID(ctx),
+		ActorID:      actorID(ctx),
+		Action:       action,
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		CreatedAt:    time.Now(),
+	}
+
+	if err := uc.repo.Create(ctx, entry); err != nil {
+		logger.ErrorContext(ctx, "Failed to record audit entry", "action", action, "resource", resourceType, "resource_id", resourceID, "error", err)
+	}
+}
+
+func (uc *AuditUseCase) ListEntries(ctx context.Context, limit, offset int) ([]*model.AuditEntry, error) {
+	logger.InfoContext(ctx, "Listing audit entries", "limit", limit, "offset", offset)
+
+	if limit <= 0 {
+		limit = 10
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	entries, err := uc.repo.List(ctx, merchantID(ctx), limit, offset)
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to list audit entries", "error", err)
+		return nil, err
+	}
+	return entries, nil
+}
+
+// actorID identifies the authenticated principal in ctx, or the system when the
+// call did not originate from an API request (e.g. a background scheduler).
+func actorID(ctx context.Context) string {
+	if principal, ok := auth.PrincipalFromContext(ctx); ok {
+		return principal.Subject
+	}
+	return model.SystemActorID
+}
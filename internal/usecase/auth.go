@@ -0,0 +1,320 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"GO-API/internal/domain/model"
+	"GO-API/internal/gateway"
+	"GO-API/internal/pkg/auth"
+	"GO-API/internal/pkg/logger"
+)
+
+const (
+	DefaultAccessTokenTTL  = 15 * time.Minute
+	DefaultRefreshTokenTTL = 30 * 24 * time.Hour
+	MaxClientNameLength    = 100
+
+	// revokedTokenRetention keeps denylist entries a little past their exp so
+	// tokens still accepted within the validation leeway stay revoked.
+	revokedTokenRetention = 5 * time.Minute
+)
+
+const (
+	TokenTypeHintAccessToken  = "access_token"
+	TokenTypeHintRefreshToken = "refresh_token"
+)
+
+const (
+	AuditResourceAPIClient = "api_client"
+
+	AuditActionAPIClientCreate = "api_client.create"
+	AuditActionTokenReuse      = "refresh_token.reuse"
+)
+
+var errInvalidClient = model.NewUnauthorizedError("invalid client credentials")
+
+type AuthUseCase struct {
+	clientRepo      gateway.APIClientRepository
+	refreshRepo     gateway.RefreshTokenRepository
+	denylist        gateway.TokenDenylist
+	jwtAuth         *auth.JWTAuth
+	audit           *AuditUseCase
+	accessTokenTTL  time.Duration
+	refreshTokenTTL time.Duration
+}
+
+func NewAuthUseCase(
+	clientRepo gateway.APIClientRepository,
+	refreshRepo gateway.RefreshTokenRepository,
+	denylist gateway.TokenDenylist,
+	jwtAuth *auth.JWTAuth,
+	audit *AuditUseCase,
+	accessTokenTTL time.Duration,
+	refreshTokenTTL time.Duration,
+) *AuthUseCase {
+	return &AuthUseCase{
+		clientRepo:      clientRepo,
+		refreshRepo:     refreshRepo,
+		denylist:        denylist,
+		jwtAuth:         jwtAuth,
+		audit:           audit,
+		accessTokenTTL:  accessTokenTTL,
+		refreshTokenTTL: refreshTokenTTL,
+	}
+}
+
+type CreateAPIClientInput struct {
+	Name     string
+	Role     string
+	Livemode bool
+}
+
+// IssuedTokens is the result of a successful grant. RefreshToken is the only
+// copy of the plaintext refresh token; the server keeps its hash.
+type IssuedTokens struct {
+	AccessToken  string
+	ExpiresIn    time.Duration
+	RefreshToken string
+}
+
+// CreateClient registers an API client and returns it together with its
+// secret, which is not stored and cannot be retrieved again.
+func (uc *AuthUseCase) CreateClient(ctx context.Context, input CreateAPIClientInput) (*model.APIClient, string, error) {
+	logger.InfoContext(ctx, "Creating API client", "name", input.Name, "role", input.Role, "livemode", input.Livemode)
+
+	if input.Name == "" {
+		return nil, "", model.NewValidationError("name is required")
+	}
+	if len(input.Name) > MaxClientNameLength {
+		return nil, "", model.NewValidationError("name is too long")
+	}
+	if !auth.ValidRole(input.Role) {
+		return nil, "", model.NewValidationError("invalid role")
+	}
+	if input.Livemode && !livemode(ctx) {
+		return nil, "", model.NewForbiddenError("live api clients cannot be created in test mode")
+	}
+
+	secret, err := auth.GenerateSecret()
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to generate client secret", "error", err)
+		return nil, "", model.NewInternalError(err)
+	}
+
+	client := &model.APIClient{
+		ID:         uuid.New().String(),
+		MerchantID: merchantID(ctx),
+		Name:       input.Name,
+		Role:       input.Role,
+		Livemode:   input.Livemode,
+		SecretHash: auth.HashSecret(secret),
+		CreatedAt:  time.Now(),
+	}
+
+	if err := uc.clientRepo.Create(ctx, client); err != nil {
+		logger.ErrorContext(ctx, "Failed to save API client", "error", err)
+		return nil, "", err
+	}
+
+	uc.audit.Record(ctx, AuditActionAPIClientCreate, AuditResourceAPIClient, client.ID)
+	return client, secret, nil
+}
+
+// ClientCredentials authenticates the client and issues a new access token
+// and the first refresh token of a new rotation family.
+func (uc *AuthUseCase) ClientCredentials(ctx context.Context, clientID, clientSecret string) (*IssuedTokens, error) {
+	client, err := uc.authenticateClient(ctx, clientID, clientSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.InfoContext(ctx, "Issuing tokens", "client_id", client.ID)
+	return uc.issue(ctx, client, uuid.New().String())
+}
+
+// Refresh exchanges a refresh token for a new token pair. Each refresh token
+// can be used once; presenting a spent token is treated as theft and revokes
+// every refresh token in its family.
+func (uc *AuthUseCase) Refresh(ctx context.Context, clientID, clientSecret, refreshToken string) (*IssuedTokens, error) {
+	client, err := uc.authenticateClient(ctx, clientID, clientSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := uc.findRefreshToken(ctx, client.ID, refreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if token.RevokedAt != nil || now.After(token.ExpiresAt) {
+		return nil, model.NewValidationError("refresh token is expired or revoked")
+	}
+
+	spent, err := uc.refreshRepo.MarkUsed(ctx, token.ID, now)
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to mark refresh token used", "error", err)
+		return nil, err
+	}
+	if !spent {
+		logger.ErrorContext(ctx, "Refresh token reuse detected", "client_id", client.ID, "family_id", token.FamilyID)
+		if err := uc.refreshRepo.RevokeFamily(ctx, token.FamilyID, now); err != nil {
+			logger.ErrorContext(ctx, "Failed to revoke refresh token family", "error", err)
+			return nil, err
+		}
+		uc.audit.Record(ctx, AuditActionTokenReuse, AuditResourceAPIClient, client.ID)
+		return nil, model.NewValidationError("refresh token is expired or revoked")
+	}
+
+	return uc.issue(ctx, client, token.FamilyID)
+}
+
+// Revoke implements RFC 7009 revocation for tokens owned by the client.
+// Revoking a refresh token revokes its whole family; revoking an access token
+// adds its jti to the denylist. Unknown or invalid tokens are not an error.
+func (uc *AuthUseCase) Revoke(ctx context.Context, clientID, clientSecret, token, tokenTypeHint string) error {
+	client, err := uc.authenticateClient(ctx, clientID, clientSecret)
+	if err != nil {
+		return err
+	}
+	if token == "" {
+		return model.NewValidationError("token is required")
+	}
+
+	if tokenTypeHint != TokenTypeHintAccessToken {
+		revoked, err := uc.revokeRefreshToken(ctx, client.ID, token)
+		if err != nil || revoked {
+			return err
+		}
+	}
+
+	return uc.revokeAccessToken(ctx, client.ID, token)
+}
+
+// PurgeRevokedTokens removes denylist entries for tokens that have expired.
+func (uc *AuthUseCase) PurgeRevokedTokens(ctx context.Context) error {
+	deleted, err := uc.denylist.DeleteExpired(ctx, time.Now().Add(-revokedTokenRetention))
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to purge revoked tokens", "error", err)
+		return err
+	}
+	if deleted > 0 {
+		logger.InfoContext(ctx, "Purged expired revoked tokens", "deleted", deleted)
+	}
+	return nil
+}
+
+func (uc *AuthUseCase) authenticateClient(ctx context.Context, clientID, clientSecret string) (*model.APIClient, error) {
+	if clientID == "" || clientSecret == "" {
+		return nil, errInvalidClient
+	}
+
+	client, err := uc.clientRepo.FindByID(ctx, clientID)
+	if err != nil {
+		if isNotFound(err) {
+			logger.InfoContext(ctx, "Unknown API client", "client_id", clientID)
+			return nil, errInvalidClient
+		}
+		logger.ErrorContext(ctx, "Failed to find API client", "error", err)
+		return nil, err
+	}
+
+	if client.DisabledAt != nil || !auth.VerifySecret(clientSecret, client.SecretHash) {
+		logger.InfoContext(ctx, "Rejected credentials for API client", "client_id", clientID)
+		return nil, errInvalidClient
+	}
+	return client, nil
+}
+
+func (uc *AuthUseCase) findRefreshToken(ctx context.Context, clientID, refreshToken string) (*model.RefreshToken, error) {
+	token, err := uc.refreshRepo.FindByHash(ctx, auth.HashSecret(refreshToken))
+	if err != nil {
+		if isNotFound(err) {
+			return nil, model.NewValidationError("invalid refresh token")
+		}
+		logger.ErrorContext(ctx, "Failed to find refresh token", "error", err)
+		return nil, err
+	}
+
+	if token.ClientID != clientID {
+		logger.InfoContext(ctx, "Refresh token presented by another client", "client_id", clientID, "owner_client_id", token.ClientID)
+		return nil, model.NewValidationError("invalid refresh token")
+	}
+	return token, nil
+}
+
+func (uc *AuthUseCase) issue(ctx context.Context, client *model.APIClient, familyID string) (*IssuedTokens, error) {
+	accessToken, _, err := uc.jwtAuth.IssueToken(client.ID, client.Role, client.MerchantID, client.Livemode, uc.accessTokenTTL)
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to issue access token", "error", err)
+		return nil, model.NewInternalError(err)
+	}
+
+	refreshToken, err := auth.GenerateSecret()
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to generate refresh token", "error", err)
+		return nil, model.NewInternalError(err)
+	}
+
+	now := time.Now()
+	record := &model.RefreshToken{
+		ID:        uuid.New().String(),
+		FamilyID:  familyID,
+		ClientID:  client.ID,
+		TokenHash: auth.HashSecret(refreshToken),
+		ExpiresAt: now.Add(uc.refreshTokenTTL),
+		CreatedAt: now,
+	}
+	if err := uc.refreshRepo.Create(ctx, record); err != nil {
+		logger.ErrorContext(ctx, "Failed to save refresh token", "error", err)
+		return nil, err
+	}
+
+	return &IssuedTokens{
+		AccessToken:  accessToken,
+		ExpiresIn:    uc.accessTokenTTL,
+		RefreshToken: refreshToken,
+	}, nil
+}
+
+func (uc *AuthUseCase) revokeRefreshToken(ctx context.Context, clientID, token string) (bool, error) {
+	record, err := uc.findRefreshToken(ctx, clientID, token)
+	if err != nil {
+		var domainErr *model.Error
+		if errors.As(err, &domainErr) && domainErr.Type == model.ErrorTypeValidation {
+			return false, nil
+		}
+		return false, err
+	}
+
+	logger.InfoContext(ctx, "Revoking refresh token family", "family_id", record.FamilyID, "client_id", clientID)
+	if err := uc.refreshRepo.RevokeFamily(ctx, record.FamilyID, time.Now()); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (uc *AuthUseCase) revokeAccessToken(ctx context.Context, clientID, token string) error {
+	claims, err := uc.jwtAuth.ValidateToken(ctx, token)
+	if err != nil {
+		logger.InfoContext(ctx, "Ignoring revocation of invalid access token", "error", err)
+		return nil
+	}
+
+	if claims.Subject() != clientID || claims.ID == "" || claims.ExpiresAt == nil {
+		logger.InfoContext(ctx, "Ignoring revocation of access token not issued to client", "client_id", clientID)
+		return nil
+	}
+
+	logger.InfoContext(ctx, "Revoking access token", "jti", claims.ID, "client_id", clientID)
+	return uc.denylist.Revoke(ctx, claims.ID, claims.ExpiresAt.Time)
+}
+
+func isNotFound(err error) bool {
+	var domainErr *model.Error
+	return errors.As(err, &domainErr) && domainErr.Type == model.ErrorTypeNotFound
+}
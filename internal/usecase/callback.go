@@ -0,0 +1,57 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"GO-API/internal/domain/model"
+	"GO-API/internal/gateway"
+	"GO-API/internal/pkg/logger"
+)
+
+type CallbackUseCase struct {
+	adapters map[string]gateway.CallbackAdapter
+	nonces   gateway.CallbackNonceRepository
+	payments *PaymentUseCase
+}
+
+// NewCallbackUseCase dispatches callbacks to adapters, keyed by the provider
+// name used in the callback URL.
+func NewCallbackUseCase(adapters map[string]gateway.CallbackAdapter, nonces gateway.CallbackNonceRepository, payments *PaymentUseCase) *CallbackUseCase {
+	return &CallbackUseCase{
+		adapters: adapters,
+		nonces:   nonces,
+		payments: payments,
+	}
+}
+
+// HandleCallback applies a callback whose signature has been verified to the
+// payment it refers to.
+func (uc *CallbackUseCase) HandleCallback(ctx context.Context, provider string, body []byte) (*model.Payment, error) {
+	adapter, ok := uc.adapters[provider]
+	if !ok {
+		return nil, model.NewNotFoundError("unknown callback provider")
+	}
+
+	callback, err := adapter.ParseCallback(body)
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to parse callback", "provider", provider, "error", err)
+		return nil, err
+	}
+
+	return uc.payments.ApplyCallback(ctx, callback)
+}
+
+// PurgeExpiredNonces removes replay protection entries whose callbacks would
+// now be rejected by the timestamp check.
+func (uc *CallbackUseCase) PurgeExpiredNonces(ctx context.Context) error {
+	deleted, err := uc.nonces.DeleteExpired(ctx, time.Now())
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to purge callback nonces", "error", err)
+		return err
+	}
+	if deleted > 0 {
+		logger.InfoContext(ctx, "Purged expired callback nonces", "deleted", deleted)
+	}
+	return nil
+}
@@ -0,0 +1,300 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"GO-API/internal/domain/model"
+	"GO-API/internal/domain/service"
+	"GO-API/internal/gateway"
+	"GO-API/internal/pkg/logger"
+)
+
+const (
+	MaxInvoiceLineItems       = 100
+	MaxLineItemDescription    = 200
+	MaxLineItemQuantity       = 1000000
+	DefaultInvoiceTaxRounding = model.TaxRoundingFloor
+)
+
+type InvoiceUseCase struct {
+	repo           gateway.InvoiceRepository
+	paymentUseCase *PaymentUseCase
+	merchants      *MerchantUseCase
+	limits         PaymentLimits
+}
+
+func NewInvoiceUseCase(repo gateway.InvoiceRepository, paymentUseCase *PaymentUseCase, merchants *MerchantUseCase, limits PaymentLimits) *InvoiceUseCase {
+	uc := &InvoiceUseCase{
+		repo:           repo,
+		paymentUseCase: paymentUseCase,
+		merchants:      merchants,
+		limits:         limits,
+	}
+	paymentUseCase.OnStatusChange(uc.handlePaymentStatusChange)
+	return uc
+}
+
+type InvoiceLineItemInput struct {
+	Description string
+	Quantity    int64
+	UnitPrice   int64
+	TaxRate     int
+}
+
+type CreateInvoiceInput struct {
+	CustomerID   string
+	CustomerName string
+	Currency     string
+	TaxInclusive bool
+	TaxRounding  string
+	LineItems    []InvoiceLineItemInput
+	DueDate      *time.Time
+}
+
+func (uc *InvoiceUseCase) CreateInvoice(ctx context.Context, input CreateInvoiceInput) (*model.Invoice, error) {
+	logger.InfoContext(ctx, "Creating invoice", "customer_id", input.CustomerID, "items", len(input.LineItems))
+
+	if input.TaxRounding == "" {
+		input.TaxRounding = string(DefaultInvoiceTaxRounding)
+	}
+
+	merchant, err := uc.merchants.Config(ctx, merchantID(ctx))
+	if err != nil {
+		return nil, err
+	}
+	if err := service.ValidateRegistrationNumber(merchant.InvoiceIssuer.RegistrationNumber); err != nil {
+		logger.ErrorContext(ctx, "Invoice issuer is not configured", "merchant_id", merchant.ID, "error", err)
+		return nil, model.NewValidationError("invoice_issuer must be configured on the merchant before issuing invoices")
+	}
+
+	if err := validateCreateInvoiceInput(input, uc.limits); err != nil {
+		logger.ErrorContext(ctx, "Invoice validation failed", "error", err)
+		return nil, err
+	}
+
+	items := make([]model.InvoiceLineItem, len(input.LineItems))
+	for i, item := range input.LineItems {
+		items[i] = model.InvoiceLineItem{
+			Description: item.Description,
+			Quantity:    item.Quantity,
+			UnitPrice:   item.UnitPrice,
+			TaxRate:     model.TaxRate(item.TaxRate),
+		}
+	}
+
+	rounding := model.TaxRounding(input.TaxRounding)
+	totals, err := service.CalculateInvoiceTotals(items, input.TaxInclusive, rounding)
+	if err != nil {
+		return nil, err
+	}
+	if totals.Total < uc.limits.MinAmount || totals.Total > uc.limits.MaxAmount {
+		return nil, model.NewValidationError("invoice total is out of range")
+	}
+
+	now := time.Now()
+	invoiceNumber, err := uc.repo.NextInvoiceNumber(ctx, merchant.ID, now)
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to allocate invoice number", "error", err)
+		return nil, model.NewInternalError(err)
+	}
+
+	invoice := &model.Invoice{
+		ID:            uuid.New().String(),
+		MerchantID:    merchantID(ctx),
+		Livemode:      livemode(ctx),
+		InvoiceNumber: invoiceNumber,
+		Issuer:        merchant.InvoiceIssuer,
+		CustomerID:    input.CustomerID,
+		CustomerName:  input.CustomerName,
+		Currency:      input.Currency,
+		Status:        model.InvoiceStatusOpen,
+		TaxInclusive:  input.TaxInclusive,
+		TaxRounding:   rounding,
+		LineItems:     items,
+		TaxSummaries:  totals.TaxSummaries,
+		Subtotal:      totals.Subtotal,
+		TaxTotal:      totals.TaxTotal,
+		Total:         totals.Total,
+		IssueDate:     now,
+		DueDate:       input.DueDate,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+
+	if err := uc.repo.Create(ctx, invoice); err != nil {
+		logger.ErrorContext(ctx, "Database error", "error", err)
+		return nil, model.NewInternalError(err)
+	}
+
+	logger.InfoContext(ctx, "Successfully created invoice", "invoice_id", invoice.ID, "number", invoice.InvoiceNumber)
+	return invoice, nil
+}
+
+func validateCreateInvoiceInput(input CreateInvoiceInput, limits PaymentLimits) error {
+	if input.CustomerID == "" {
+		return model.NewValidationError("customer_id is required")
+	}
+	if input.CustomerName == "" {
+		return model.NewValidationError("customer_name is required")
+	}
+	if input.Currency != CurrencyJPY {
+		return model.NewValidationError("invoices only support JPY")
+	}
+	if !service.ValidTaxRounding(model.TaxRounding(input.TaxRounding)) {
+		return model.NewValidationError("unsupported tax_rounding")
+	}
+	if len(input.LineItems) == 0 {
+		return model.NewValidationError("at least one line item is required")
+	}
+	if len(input.LineItems) > MaxInvoiceLineItems {
+		return model.NewValidationError("too many line items")
+	}
+
+	for _, item := range input.LineItems {
+		if item.Description == "" || len(item.Description) > MaxLineItemDescription {
+			return model.NewValidationError("line item description is required and must be at most 200 characters")
+		}
+		if item.Quantity <= 0 || item.Quantity > MaxLineItemQuantity {
+			return model.NewValidationError("line item quantity is out of range")
+		}
+		if item.UnitPrice < 0 || item.UnitPrice > limits.MaxAmount {
+			return model.NewValidationError("line item unit_price is out of range")
+		}
+		if !service.ValidTaxRate(model.TaxRate(item.TaxRate)) {
+			return model.NewValidationError("line item tax_rate must be 10 or 8")
+		}
+	}
+
+	return nil
+}
+
+func (uc *InvoiceUseCase) GetInvoice(ctx context.Context, id string) (*model.Invoice, error) {
+	logger.InfoContext(ctx, "Getting invoice", "invoice_id", id)
+
+	invoice, err := uc.repo.FindByID(ctx, merchantID(ctx), livemode(ctx), id)
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to find invoice", "error", err)
+		return nil, err
+	}
+	return invoice, nil
+}
+
+func (uc *InvoiceUseCase) ListInvoices(ctx context.Context, limit, offset int) ([]*model.Invoice, error) {
+	logger.InfoContext(ctx, "Listing invoices", "limit", limit, "offset", offset)
+
+	if limit <= 0 {
+		limit = 10
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	invoices, err := uc.repo.List(ctx, merchantID(ctx), livemode(ctx), limit, offset)
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to list invoices", "error", err)
+		return nil, err
+	}
+	return invoices, nil
+}
+
+// PayInvoice creates a payment for the invoice total and links it to the
+// invoice. The invoice is marked paid once that payment completes, which may
+// happen immediately or later for asynchronous payment methods. A new payment
+// is only started while the invoice has none that may still complete.
+func (uc *InvoiceUseCase) PayInvoice(ctx context.Context, id string, paymentMethod string) (*model.Invoice, error) {
+	logger.InfoContext(ctx, "Paying invoice", "invoice_id", id, "payment_method", paymentMethod)
+
+	paymentID := uuid.New().String()
+	invoice, err := uc.repo.ReservePayment(ctx, merchantID(ctx), livemode(ctx), id, func(invoice *model.Invoice) error {
+		if invoice.Status == model.InvoiceStatusPaid {
+			return model.NewValidationError("invoice is already paid")
+		}
+		if invoice.PaymentID != "" {
+			if err := uc.checkPaymentRetryable(ctx, invoice.PaymentID); err != nil {
+				return err
+			}
+		}
+		invoice.PaymentID = paymentID
+		return nil
+	})
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to reserve invoice payment", "error", err)
+		return nil, err
+	}
+
+	_, err = uc.paymentUseCase.CreatePayment(ctx, CreatePaymentInput{
+		ID:            paymentID,
+		Amount:        invoice.Total,
+		Currency:      invoice.Currency,
+		Description:   fmt.Sprintf("Invoice %s", invoice.InvoiceNumber),
+		CustomerID:    invoice.CustomerID,
+		PaymentMethod: paymentMethod,
+		OrderID:       invoice.InvoiceNumber,
+		InvoiceID:     invoice.ID,
+	})
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to create invoice payment", "error", err)
+		return nil, err
+	}
+
+	return uc.repo.FindByID(ctx, merchantID(ctx), livemode(ctx), invoice.ID)
+}
+
+// checkPaymentRetryable reports a validation error unless the invoice's
+// current payment has failed, was canceled, or was reserved but never created.
+func (uc *InvoiceUseCase) checkPaymentRetryable(ctx context.Context, paymentID string) error {
+	payment, err := uc.paymentUseCase.GetPayment(ctx, paymentID)
+	if isNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to find invoice payment", "error", err)
+		return model.NewInternalError(err)
+	}
+
+	switch payment.Status {
+	case model.PaymentStatusFailed, model.PaymentStatusCanceled:
+		return nil
+	default:
+		return model.NewValidationError("invoice already has a payment in progress")
+	}
+}
+
+func (uc *InvoiceUseCase) handlePaymentStatusChange(ctx context.Context, payment *model.Payment) {
+	invoiceID := payment.Metadata.InvoiceID
+	if invoiceID == "" {
+		return
+	}
+
+	invoice, err := uc.repo.FindByID(ctx, payment.MerchantID, payment.Livemode, invoiceID)
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to find invoice for payment", "invoice_id", invoiceID, "payment_id", payment.ID, "error", err)
+		return
+	}
+
+	if invoice.Status == model.InvoiceStatusPaid {
+		return
+	}
+	if invoice.PaymentID != "" && invoice.PaymentID != payment.ID {
+		logger.InfoContext(ctx, "Ignoring status change of superseded invoice payment", "invoice_id", invoice.ID, "payment_id", payment.ID)
+		return
+	}
+
+	invoice.PaymentID = payment.ID
+	if payment.Status == model.PaymentStatusCompleted {
+		paidAt := time.Now()
+		invoice.Status = model.InvoiceStatusPaid
+		invoice.PaidAt = &paidAt
+	}
+
+	if err := uc.repo.Update(ctx, invoice); err != nil {
+		logger.ErrorContext(ctx, "Failed to update invoice", "invoice_id", invoice.ID, "error", err)
+		return
+	}
+
+	logger.InfoContext(ctx, "Invoice linked to payment", "invoice_id", invoice.ID, "payment_id", payment.ID, "status", payment.Status)
+}
@@ -0,0 +1,204 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+
+	"GO-API/internal/domain/model"
+	"GO-API/internal/domain/service"
+	"GO-API/internal/gateway"
+	"GO-API/internal/pkg/logger"
+)
+
+const (
+	DefaultTransactionIDPrefix = "PAY"
+	MaxMerchantNameLength      = 100
+	MaxIssuerAddressLength     = 200
+)
+
+const (
+	AuditResourceMerchant = "merchant"
+
+	AuditActionMerchantUpdate = "merchant.update"
+)
+
+var transactionIDPrefixPattern = regexp.MustCompile(`^[A-Z0-9]{2,10}$`)
+
+// SupportedPaymentMethods are what the platform can process; each merchant
+// may enable a subset of them.
+var SupportedPaymentMethods = []string{"credit_card", "bank_transfer", "convenience_store"}
+
+// PaymentLimits are the platform wide limits. They bound every payment and
+// invoice, and merchants may narrow them but not widen them.
+type PaymentLimits struct {
+	MinAmount  int64
+	MaxAmount  int64
+	Currencies []string
+}
+
+type MerchantUseCase struct {
+	repo   gateway.MerchantRepository
+	audit  *AuditUseCase
+	limits PaymentLimits
+	// defaultIssuer is the invoice issuer of model.DefaultMerchantID until it
+	// configures its own. Other merchants have no issuer until they set one.
+	defaultIssuer model.InvoiceIssuer
+}
+
+func NewMerchantUseCase(repo gateway.MerchantRepository, audit *AuditUseCase, limits PaymentLimits, defaultIssuer model.InvoiceIssuer) *MerchantUseCase {
+	return &MerchantUseCase{
+		repo:          repo,
+		audit:         audit,
+		limits:        limits,
+		defaultIssuer: defaultIssuer,
+	}
+}
+
+type UpdateMerchantInput struct {
+	Name                string
+	AllowedCurrencies   []string
+	PaymentMethods      []string
+	TransactionIDPrefix string
+	MinAmount           int64
+	MaxAmount           int64
+	InvoiceIssuer       model.InvoiceIssuer
+}
+
+// Config returns the configuration of merchantID, falling back to the
+// platform defaults for merchants that have not been configured yet.
+func (uc *MerchantUseCase) Config(ctx context.Context, merchantID string) (*model.Merchant, error) {
+	if merchantID == "" {
+		return nil, model.NewUnauthorizedError("no merchant associated with the credentials")
+	}
+
+	merchant, err := uc.repo.FindByID(ctx, merchantID)
+	if isNotFound(err) {
+		merchant, err = defaultMerchant(merchantID, uc.limits), nil
+	}
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to find merchant", "merchant_id", merchantID, "error", err)
+		return nil, err
+	}
+	if merchant.ID == model.DefaultMerchantID && merchant.InvoiceIssuer == (model.InvoiceIssuer{}) {
+		merchant.InvoiceIssuer = uc.defaultIssuer
+	}
+	return merchant, nil
+}
+
+// RateLimitPlan returns the plan whose rate limits apply to merchantID.
+func (uc *MerchantUseCase) RateLimitPlan(ctx context.Context, merchantID string) (string, error) {
+	merchant, err := uc.Config(ctx, merchantID)
+	if err != nil {
+		return "", err
+	}
+	return merchant.Plan, nil
+}
+
+func (uc *MerchantUseCase) GetMerchant(ctx context.Context) (*model.Merchant, error) {
+	return uc.Config(ctx, merchantID(ctx))
+}
+
+// UpdateMerchant replaces the configuration of the merchant acting in ctx.
+func (uc *MerchantUseCase) UpdateMerchant(ctx context.Context, input UpdateMerchantInput) (*model.Merchant, error) {
+	merchant, err := uc.Config(ctx, merchantID(ctx))
+	if err != nil {
+		return nil, err
+	}
+	logger.InfoContext(ctx, "Updating merchant", "merchant_id", merchant.ID)
+
+	if err := validateUpdateMerchantInput(input, uc.limits); err != nil {
+		logger.ErrorContext(ctx, "Merchant validation failed", "error", err)
+		return nil, err
+	}
+
+	now := time.Now()
+	merchant.Name = input.Name
+	merchant.AllowedCurrencies = input.AllowedCurrencies
+	merchant.PaymentMethods = input.PaymentMethods
+	merchant.TransactionIDPrefix = input.TransactionIDPrefix
+	merchant.MinAmount = input.MinAmount
+	merchant.MaxAmount = input.MaxAmount
+	merchant.InvoiceIssuer = input.InvoiceIssuer
+	merchant.UpdatedAt = now
+	if merchant.CreatedAt.IsZero() {
+		merchant.CreatedAt = now
+	}
+
+	if err := uc.repo.Save(ctx, merchant); err != nil {
+		logger.ErrorContext(ctx, "Failed to save merchant", "error", err)
+		return nil, model.NewInternalError(err)
+	}
+
+	uc.audit.Record(ctx, AuditActionMerchantUpdate, AuditResourceMerchant, merchant.ID)
+	return merchant, nil
+}
+
+func validateUpdateMerchantInput(input UpdateMerchantInput, limits PaymentLimits) error {
+	if len(input.Name) > MaxMerchantNameLength {
+		return model.NewValidationError("name is too long")
+	}
+	if len(input.AllowedCurrencies) == 0 {
+		return model.NewValidationError("at least one currency is required")
+	}
+	for _, currency := range input.AllowedCurrencies {
+		if !contains(limits.Currencies, currency) {
+			return model.NewValidationError("unsupported currency: " + currency)
+		}
+	}
+	if len(input.PaymentMethods) == 0 {
+		return model.NewValidationError("at least one payment method is required")
+	}
+	for _, method := range input.PaymentMethods {
+		if !contains(SupportedPaymentMethods, method) {
+			return model.NewValidationError("unsupported payment method: " + method)
+		}
+	}
+	if !transactionIDPrefixPattern.MatchString(input.TransactionIDPrefix) {
+		return model.NewValidationError("transaction_id_prefix must be 2-10 uppercase letters or digits")
+	}
+	if input.MinAmount < limits.MinAmount || input.MaxAmount > limits.MaxAmount || input.MinAmount > input.MaxAmount {
+		return model.NewValidationError(fmt.Sprintf(
+			"min_amount and max_amount must satisfy %d <= min_amount <= max_amount <= %d", limits.MinAmount, limits.MaxAmount))
+	}
+	return validateInvoiceIssuer(input.InvoiceIssuer)
+}
+
+// validateInvoiceIssuer accepts an empty issuer, which leaves the merchant
+// unable to issue invoices, or a complete one.
+func validateInvoiceIssuer(issuer model.InvoiceIssuer) error {
+	if issuer == (model.InvoiceIssuer{}) {
+		return nil
+	}
+	if issuer.Name == "" {
+		return model.NewValidationError("invoice_issuer.name is required")
+	}
+	if len(issuer.Name) > MaxMerchantNameLength {
+		return model.NewValidationError("invoice_issuer.name is too long")
+	}
+	if len(issuer.Address) > MaxIssuerAddressLength {
+		return model.NewValidationError("invoice_issuer.address is too long")
+	}
+	return service.ValidateRegistrationNumber(issuer.RegistrationNumber)
+}
+
+func defaultMerchant(id string, limits PaymentLimits) *model.Merchant {
+	return &model.Merchant{
+		ID:                  id,
+		AllowedCurrencies:   append([]string(nil), limits.Currencies...),
+		PaymentMethods:      append([]string(nil), SupportedPaymentMethods...),
+		TransactionIDPrefix: DefaultTransactionIDPrefix,
+		MinAmount:           limits.MinAmount,
+		MaxAmount:           limits.MaxAmount,
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -2,7 +2,6 @@ package usecase
 
 import (
 	"context"
-	"log"
 	"time"
 
 	"github.com/google/uuid"
@@ -11,14 +10,22 @@ import (
 	"GO-API/internal/domain/service"
 	"GO-API/internal/gateway"
 	"GO-API/internal/pkg/logger"
+	"GO-API/internal/pkg/metrics"
+	"GO-API/internal/pkg/tracing"
 )
 
 type PaymentUseCase struct {
-	repo          gateway.PaymentRepository
-	processor     gateway.PaymentProcessor
-	txIDGenerator *service.PaymentTransactionIDGenerator
+	repo      gateway.PaymentRepository
+	processor gateway.PaymentProcessor
+	merchants *MerchantUseCase
+	audit     *AuditUseCase
+	listeners []PaymentStatusListener
 }
 
+// PaymentStatusListener is notified after a payment's status has been
+// persisted with a new value.
+type PaymentStatusListener func(ctx context.Context, payment *model.Payment)
+
 const (
 	CurrencyJPY = "JPY"
 	CurrencyUSD = "USD"
@@ -27,76 +34,171 @@ const (
 const (
 	MaxDescriptionLength = 500
 	MaxCustomerIDLength  = 100
-	MinAmount            = 1
-	MaxAmount            = 10000000
+	MaxScheduleAhead     = 365 * 24 * time.Hour
+	ScheduledBatchSize   = 100
+	// ScheduledClaimDuration is how long a replica holds scheduled payments
+	// it claimed before another replica may process them again. Retries keep
+	// the payment's transaction ID so the processor can recognise them.
+	ScheduledClaimDuration = 10 * time.Minute
+)
+
+const (
+	AuditResourcePayment = "payment"
+
+	AuditActionPaymentCreate     = "payment.create"
+	AuditActionPaymentReschedule = "payment.reschedule"
+	AuditActionPaymentCancel     = "payment.cancel"
+	AuditActionPaymentCallback   = "payment.callback"
 )
 
-func NewPaymentUseCase(repo gateway.PaymentRepository, processor gateway.PaymentProcessor) *PaymentUseCase {
+func NewPaymentUseCase(repo gateway.PaymentRepository, processor gateway.PaymentProcessor, merchants *MerchantUseCase, audit *AuditUseCase) *PaymentUseCase {
 	return &PaymentUseCase{
-		repo:          repo,
-		processor:     processor,
-		txIDGenerator: service.NewPaymentTransactionIDGenerator(),
+		repo:      repo,
+		processor: processor,
+		merchants: merchants,
+		audit:     audit,
 	}
 }
 
 type CreatePaymentInput struct {
-	Amount        int64
-	Currency      string
-	Description   string
-	CustomerID    string
-	PaymentMethod string
-	OrderID       string
+	// ID is assigned to the payment when set, for callers that must record a
+	// reference to the payment before creating it.
+	ID             string
+	Amount         int64
+	Currency       string
+	Description    string
+	CustomerID     string
+	PaymentMethod  string
+	OrderID        string
+	SubscriptionID string
+	InvoiceID      string
+	Installment    *model.Installment
+	ScheduledAt    *time.Time
+	// IdempotencyKey makes creation idempotent: a second call with the same
+	// key returns the payment created by the first instead of charging again.
+	IdempotencyKey string
 }
 
-func (uc *PaymentUseCase) CreatePayment(ctx context.Context, input CreatePaymentInput) (*model.Payment, error) {
-	logger.Info("Creating payment with amount=%d currency=%s", input.Amount, input.Currency)
+func (uc *PaymentUseCase) CreatePayment(ctx context.Context, input CreatePaymentInput) (_ *model.Payment, err error) {
+	ctx, span := tracing.Start(ctx, "PaymentUseCase.CreatePayment")
+	defer tracing.End(span, &err)
+
+	logger.InfoContext(ctx, "Creating payment", "amount", input.Amount, "currency", input.Currency)
 
-	if err := validateCreatePaymentInput(input); err != nil {
-		logger.Error("Payment validation failed: %v", err)
-		log.Printf("Validation error: %v", err)
+	merchant, err := uc.merchants.Config(ctx, merchantID(ctx))
+	if err != nil {
 		return nil, err
 	}
 
-	transactionID, err := uc.txIDGenerator.Generate()
+	if err := validateCreatePaymentInput(ctx, input, merchant); err != nil {
+		logger.ErrorContext(ctx, "Payment validation failed", "error", err)
+		return nil, err
+	}
+
+	transactionID, err := service.NewTransactionIDGenerator(merchant.TransactionIDPrefix).Generate()
 	if err != nil {
-		logger.Error("Failed to generate transaction ID: %v", err)
-		log.Printf("Transaction ID generation error: %v", err)
+		logger.ErrorContext(ctx, "Failed to generate transaction ID", "error", err)
 		return nil, model.NewInternalError(err)
 	}
-	log.Printf("Generated transaction ID: %s", transactionID)
+	logger.DebugContext(ctx, "Generated transaction ID", "transaction_id", transactionID)
 
 	payment := &model.Payment{
-		ID:            uuid.New().String(),
-		Amount:        input.Amount,
-		Currency:      input.Currency,
-		Status:        model.PaymentStatusPending,
-		Description:   input.Description,
-		CustomerID:    input.CustomerID,
-		CreatedAt:     time.Now(),
-		UpdatedAt:     time.Now(),
-		TransactionID: transactionID,
+		ID:             uuid.New().String(),
+		MerchantID:     merchant.ID,
+		Livemode:       livemode(ctx),
+		Amount:         input.Amount,
+		Currency:       input.Currency,
+		Status:         model.PaymentStatusPending,
+		Description:    input.Description,
+		CustomerID:     input.CustomerID,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+		TransactionID:  transactionID,
+		CreatedBy:      actorID(ctx),
+		IdempotencyKey: input.IdempotencyKey,
 		Metadata: model.PaymentMetadata{
-			OrderID:       input.OrderID,
-			PaymentMethod: input.PaymentMethod,
+			OrderID:        input.OrderID,
+			PaymentMethod:  input.PaymentMethod,
+			SubscriptionID: input.SubscriptionID,
+			InvoiceID:      input.InvoiceID,
 		},
 	}
-	log.Printf("Created payment object: %+v", payment)
+	if input.ScheduledAt != nil {
+		payment.Status = model.PaymentStatusScheduled
+		payment.ScheduledAt = input.ScheduledAt
+	}
+	if input.Installment != nil {
+		purchasedAt := payment.CreatedAt
+		if payment.ScheduledAt != nil {
+			purchasedAt = *payment.ScheduledAt
+		}
+		payment.Metadata.Installment = &model.Installment{
+			Type:     input.Installment.Type,
+			Count:    input.Installment.Count,
+			Schedule: service.BuildInstallmentSchedule(payment.Amount, input.Installment, purchasedAt),
+		}
+	}
+	ctx = logger.WithPaymentID(ctx, payment.ID)
+	logger.DebugContext(ctx, "Created payment object", "payment", payment)
 
-	if err := uc.repo.Create(payment); err != nil {
-		logger.Error("Database error: %v", err)
+	created, err := uc.repo.Create(ctx, payment)
+	if err != nil {
+		logger.ErrorContext(ctx, "Database error", "error", err)
 		return nil, model.NewInternalError(err)
 	}
+	if !created {
+		// An earlier or concurrent call with the same idempotency key won.
+		existing, err := uc.repo.FindByIdempotencyKey(ctx, payment.MerchantID, payment.Livemode, payment.IdempotencyKey)
+		if err != nil {
+			logger.ErrorContext(ctx, "Failed to find payment for idempotency key", "error", err)
+			return nil, model.NewInternalError(err)
+		}
+		logger.InfoContext(ctx, "Payment already created for idempotency key", "existing_payment_id", existing.ID)
+		return existing, nil
+	}
+	uc.audit.Record(ctx, AuditActionPaymentCreate, AuditResourcePayment, payment.ID)
+	observePayment(payment)
 
-	if err := uc.processor.Process(payment); err != nil {
-		logger.Error("Processing error: %v", err)
+	if payment.Status == model.PaymentStatusScheduled {
+		logger.InfoContext(ctx, "Scheduled payment", "scheduled_at", *payment.ScheduledAt)
+		return payment, nil
+	}
+
+	if err := uc.processor.Process(ctx, payment); err != nil {
+		logger.ErrorContext(ctx, "Processing error", "error", err)
 		return nil, model.NewInternalError(err)
 	}
 
-	logger.Info("Successfully processed payment: ID=%s", payment.ID)
+	if payment.Status != model.PaymentStatusPending {
+		if err := uc.repo.Update(ctx, payment); err != nil {
+			logger.ErrorContext(ctx, "Failed to update payment status", "error", err)
+			return nil, model.NewInternalError(err)
+		}
+		uc.notifyStatusChange(ctx, payment)
+	}
+
+	logger.InfoContext(ctx, "Successfully processed payment")
 	return payment, nil
 }
 
-func validateCreatePaymentInput(input CreatePaymentInput) error {
+func (uc *PaymentUseCase) OnStatusChange(listener PaymentStatusListener) {
+	uc.listeners = append(uc.listeners, listener)
+}
+
+func (uc *PaymentUseCase) notifyStatusChange(ctx context.Context, payment *model.Payment) {
+	observePayment(payment)
+	for _, listener := range uc.listeners {
+		listener(ctx, payment)
+	}
+}
+
+func observePayment(payment *model.Payment) {
+	metrics.ObservePayment(string(payment.Status), payment.Currency, payment.Metadata.PaymentMethod, payment.Amount)
+}
+
+// validateCreatePaymentInput checks input against the limits, currencies and
+// payment methods configured for merchant.
+func validateCreatePaymentInput(ctx context.Context, input CreatePaymentInput, merchant *model.Merchant) error {
 	if input.Amount <= 0 {
 		return model.NewValidationError("amount must be positive")
 	}
@@ -108,90 +210,279 @@ func validateCreatePaymentInput(input CreatePaymentInput) error {
 	}
 
 	if input.Amount <= 0 {
-		logger.Error("Invalid amount: %d", input.Amount)
+		logger.ErrorContext(ctx, "Invalid amount", "amount", input.Amount)
 		return model.NewValidationError("amount must be positive")
 	}
 
-	if input.Amount > MaxAmount {
-		logger.Error("Amount exceeds maximum: %d", input.Amount)
+	if input.Amount < merchant.MinAmount {
+		logger.ErrorContext(ctx, "Amount below minimum", "amount", input.Amount)
+		return model.NewValidationError("amount is below minimum allowed")
+	}
+
+	if input.Amount > merchant.MaxAmount {
+		logger.ErrorContext(ctx, "Amount exceeds maximum", "amount", input.Amount)
 		return model.NewValidationError("amount exceeds maximum allowed")
 	}
 
 	if input.Currency == "" {
-		logger.Error("Currency is empty")
+		logger.ErrorContext(ctx, "Currency is empty")
 		return model.NewValidationError("currency is required")
 	}
 
-	validCurrencies := map[string]bool{
-		CurrencyJPY: true,
-		CurrencyUSD: true,
-	}
-	if !validCurrencies[input.Currency] {
-		logger.Error("Unsupported currency: %s", input.Currency)
+	if !merchant.AllowsCurrency(input.Currency) {
+		logger.ErrorContext(ctx, "Unsupported currency", "currency", input.Currency)
 		return model.NewValidationError("unsupported currency")
 	}
 
-	if err := validatePaymentMethod(input.PaymentMethod); err != nil {
-		logger.Error("Invalid payment method: %s", input.PaymentMethod)
+	if err := validatePaymentMethod(input.PaymentMethod, merchant); err != nil {
+		logger.ErrorContext(ctx, "Invalid payment method", "payment_method", input.PaymentMethod)
+		return err
+	}
+
+	if err := service.ValidateInstallment(input.PaymentMethod, input.Amount, input.Installment); err != nil {
+		logger.ErrorContext(ctx, "Invalid installment option", "error", err)
 		return err
 	}
 
-	logger.Debug("Validation passed for payment: amount=%d currency=%s customer=%s",
-		input.Amount, input.Currency, input.CustomerID)
+	if input.ScheduledAt != nil {
+		if err := validateScheduledAt(*input.ScheduledAt); err != nil {
+			logger.ErrorContext(ctx, "Invalid scheduled_at", "error", err)
+			return err
+		}
+	}
+
+	logger.DebugContext(ctx, "Validation passed for payment", "amount", input.Amount, "currency", input.Currency, "customer_id", input.CustomerID)
 
 	return nil
 }
 
-func validatePaymentMethod(method string) error {
-	validMethods := map[string]bool{
-		"credit_card":       true,
-		"bank_transfer":     true,
-		"convenience_store": true,
+func validateScheduledAt(scheduledAt time.Time) error {
+	now := time.Now()
+	if !scheduledAt.After(now) {
+		return model.NewValidationError("scheduled_at must be in the future")
 	}
+	if scheduledAt.After(now.Add(MaxScheduleAhead)) {
+		return model.NewValidationError("scheduled_at is too far in the future")
+	}
+	return nil
+}
 
+func validatePaymentMethod(method string, merchant *model.Merchant) error {
 	if method == "" {
 		return model.NewValidationError("payment_method is required")
 	}
 
-	if !validMethods[method] {
+	if !merchant.AllowsPaymentMethod(method) {
 		return model.NewValidationError("unsupported payment method")
 	}
 
 	return nil
 }
 
-func (uc *PaymentUseCase) GetPayment(ctx context.Context, id string) (*model.Payment, error) {
-	logger.Info("Getting payment by ID; %s", id)
+func (uc *PaymentUseCase) GetPayment(ctx context.Context, id string) (_ *model.Payment, err error) {
+	ctx, span := tracing.Start(ctx, "PaymentUseCase.GetPayment")
+	defer tracing.End(span, &err)
+
+	ctx = logger.WithPaymentID(ctx, id)
+	logger.InfoContext(ctx, "Getting payment")
 
-	payment, err := uc.repo.FindByID(id)
+	payment, err := uc.find(ctx, id)
 	if err != nil {
-		logger.Error("Faild to find payment; %v", err)
+		logger.ErrorContext(ctx, "Faild to find payment", "error", err)
 		return nil, err
 	}
 
-	logger.Debug("Found payment; %+v", payment)
+	logger.DebugContext(ctx, "Found payment", "payment", payment)
 	return payment, nil
 }
 
-func (uc *PaymentUseCase) ListPayments(ctx context.Context, limit, offset int) ([]*model.Payment, error) {
-	logger.Info("Listing payments with limit=%d offset=%d", limit, offset)
+func (uc *PaymentUseCase) ListPayments(ctx context.Context, limit, offset int) (_ []*model.Payment, err error) {
+	ctx, span := tracing.Start(ctx, "PaymentUseCase.ListPayments")
+	defer tracing.End(span, &err)
+
+	logger.InfoContext(ctx, "Listing payments", "limit", limit, "offset", offset)
 
 	if limit <= 0 {
 		limit = 10
-		logger.Debug("Using default limit value: 10")
+		logger.DebugContext(ctx, "Using default limit value: 10")
 	}
 
 	if offset < 0 {
 		offset = 0
-		logger.Debug("Adjusting negative offset to 0")
+		logger.DebugContext(ctx, "Adjusting negative offset to 0")
 	}
 
-	payments, err := uc.repo.List(limit, offset)
+	payments, err := uc.repo.List(ctx, merchantID(ctx), livemode(ctx), limit, offset)
 	if err != nil {
-		logger.Error("Failed to list payments: %v", err)
+		logger.ErrorContext(ctx, "Failed to list payments", "error", err)
 		return nil, err
 	}
 
-	logger.Info("Successfully retrieved %d payments", len(payments))
+	logger.InfoContext(ctx, "Successfully retrieved payments", "count", len(payments))
 	return payments, nil
 }
+
+func (uc *PaymentUseCase) ReschedulePayment(ctx context.Context, id string, scheduledAt time.Time) (_ *model.Payment, err error) {
+	ctx, span := tracing.Start(ctx, "PaymentUseCase.ReschedulePayment")
+	defer tracing.End(span, &err)
+
+	ctx = logger.WithPaymentID(ctx, id)
+	logger.InfoContext(ctx, "Rescheduling payment", "scheduled_at", scheduledAt)
+
+	if err := validateScheduledAt(scheduledAt); err != nil {
+		return nil, err
+	}
+
+	payment, err := uc.findScheduled(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	payment.ScheduledAt = &scheduledAt
+	if err := uc.updateScheduled(ctx, payment); err != nil {
+		return nil, err
+	}
+	uc.audit.Record(ctx, AuditActionPaymentReschedule, AuditResourcePayment, payment.ID)
+
+	logger.InfoContext(ctx, "Successfully rescheduled payment")
+	return payment, nil
+}
+
+func (uc *PaymentUseCase) CancelScheduledPayment(ctx context.Context, id string) (_ *model.Payment, err error) {
+	ctx, span := tracing.Start(ctx, "PaymentUseCase.CancelScheduledPayment")
+	defer tracing.End(span, &err)
+
+	ctx = logger.WithPaymentID(ctx, id)
+	logger.InfoContext(ctx, "Canceling scheduled payment")
+
+	payment, err := uc.findScheduled(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	payment.Status = model.PaymentStatusCanceled
+	if err := uc.updateScheduled(ctx, payment); err != nil {
+		return nil, err
+	}
+	uc.audit.Record(ctx, AuditActionPaymentCancel, AuditResourcePayment, payment.ID)
+	uc.notifyStatusChange(ctx, payment)
+
+	logger.InfoContext(ctx, "Successfully canceled scheduled payment")
+	return payment, nil
+}
+
+// find loads a payment of the merchant acting in ctx. Payments made in the
+// other mode are reported as not found.
+func (uc *PaymentUseCase) find(ctx context.Context, id string) (*model.Payment, error) {
+	payment, err := uc.repo.FindByID(ctx, merchantID(ctx), id)
+	if err != nil {
+		return nil, err
+	}
+	if payment.Livemode != livemode(ctx) {
+		return nil, model.NewNotFoundError("payment not found")
+	}
+	return payment, nil
+}
+
+func (uc *PaymentUseCase) findScheduled(ctx context.Context, id string) (*model.Payment, error) {
+	payment, err := uc.find(ctx, id)
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to find payment", "error", err)
+		return nil, err
+	}
+	if payment.Status != model.PaymentStatusScheduled {
+		return nil, model.NewValidationError("payment is not scheduled")
+	}
+	return payment, nil
+}
+
+// updateScheduled persists changes to a scheduled payment unless the
+// scheduler has claimed it in the meantime.
+func (uc *PaymentUseCase) updateScheduled(ctx context.Context, payment *model.Payment) error {
+	updated, err := uc.repo.UpdateIfStatus(ctx, payment, model.PaymentStatusScheduled)
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to update payment", "error", err)
+		return model.NewInternalError(err)
+	}
+	if !updated {
+		return model.NewValidationError("payment is already being processed")
+	}
+	return nil
+}
+
+// ApplyCallback moves a pending or processing payment to the final status
+// reported by its processor. Repeated notifications of the status the payment
+// already has are accepted without changes.
+func (uc *PaymentUseCase) ApplyCallback(ctx context.Context, callback *model.PaymentCallback) (_ *model.Payment, err error) {
+	ctx, span := tracing.Start(ctx, "PaymentUseCase.ApplyCallback")
+	defer tracing.End(span, &err)
+
+	logger.InfoContext(ctx, "Applying callback", "transaction_id", callback.TransactionID, "status", callback.Status)
+
+	payment, err := uc.repo.FindByTransactionID(ctx, callback.TransactionID)
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to find payment for callback", "error", err)
+		return nil, err
+	}
+	ctx = logger.WithPaymentID(withScope(ctx, payment.MerchantID, payment.Livemode), payment.ID)
+
+	if payment.Status == callback.Status {
+		return payment, nil
+	}
+
+	previous := payment.Status
+	if previous != model.PaymentStatusPending && previous != model.PaymentStatusProcessing {
+		return nil, model.NewValidationError("payment is not awaiting a processor callback")
+	}
+
+	payment.Status = callback.Status
+	updated, err := uc.repo.UpdateIfStatus(ctx, payment, previous)
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to update payment", "error", err)
+		return nil, model.NewInternalError(err)
+	}
+	if !updated {
+		return nil, model.NewValidationError("payment was updated concurrently")
+	}
+
+	uc.audit.Record(ctx, AuditActionPaymentCallback, AuditResourcePayment, payment.ID)
+	uc.notifyStatusChange(ctx, payment)
+
+	logger.InfoContext(ctx, "Payment status updated by callback", "previous_status", previous, "status", payment.Status)
+	return payment, nil
+}
+
+// ProcessDueScheduledPayments claims scheduled payments that have reached
+// their scheduled_at and sends them to the processor. It is invoked
+// periodically by the scheduler.
+func (uc *PaymentUseCase) ProcessDueScheduledPayments(ctx context.Context) (err error) {
+	ctx, span := tracing.Start(ctx, "PaymentUseCase.ProcessDueScheduledPayments")
+	defer tracing.End(span, &err)
+
+	now := time.Now()
+	payments, err := uc.repo.ClaimDueScheduled(ctx, now, now.Add(ScheduledClaimDuration), ScheduledBatchSize)
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to claim scheduled payments", "error", err)
+		return err
+	}
+
+	if len(payments) > 0 {
+		logger.InfoContext(ctx, "Processing scheduled payments", "count", len(payments))
+	}
+
+	for _, payment := range payments {
+		paymentCtx := logger.WithPaymentID(withScope(ctx, payment.MerchantID, payment.Livemode), payment.ID)
+		if err := uc.processor.Process(paymentCtx, payment); err != nil {
+			logger.ErrorContext(paymentCtx, "Processing error for scheduled payment", "error", err)
+			payment.Status = model.PaymentStatusFailed
+		}
+
+		if err := uc.repo.Update(paymentCtx, payment); err != nil {
+			logger.ErrorContext(paymentCtx, "Failed to update scheduled payment", "error", err)
+			continue
+		}
+		uc.notifyStatusChange(paymentCtx, payment)
+	}
+
+	return nil
+}
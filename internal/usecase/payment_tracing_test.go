@@ -0,0 +1,66 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"GO-API/internal/domain/model"
+	"GO-API/internal/gateway"
+	"GO-API/internal/pkg/tracing/tracingtest"
+	"GO-API/internal/usecase"
+)
+
+// paymentRepository serves FindByID from payments; other methods are not
+// used by these tests.
+type paymentRepository struct {
+	gateway.PaymentRepository
+	payments map[string]*model.Payment
+	// spans records the span active in each FindByID call.
+	spans []trace.SpanContext
+}
+
+func (r *paymentRepository) FindByID(ctx context.Context, merchantID string, id string) (*model.Payment, error) {
+	r.spans = append(r.spans, trace.SpanContextFromContext(ctx))
+	payment, ok := r.payments[id]
+	if !ok {
+		return nil, model.NewNotFoundError("payment not found")
+	}
+	return payment, nil
+}
+
+func TestGetPaymentRecordsSpan(t *testing.T) {
+	exporter := tracingtest.Record(t)
+
+	repo := &paymentRepository{payments: map[string]*model.Payment{
+		"pay_1": {ID: "pay_1", Livemode: true},
+	}}
+	uc := usecase.NewPaymentUseCase(repo, nil, nil, nil)
+	if _, err := uc.GetPayment(context.Background(), "pay_1"); err != nil {
+		t.Fatal(err)
+	}
+
+	span := tracingtest.Span(t, exporter, "PaymentUseCase.GetPayment")
+	if span.Status.Code == codes.Error {
+		t.Errorf("span status = error, want unset")
+	}
+	if len(repo.spans) != 1 || repo.spans[0].SpanID() != span.SpanContext.SpanID() {
+		t.Errorf("repository was not called within the use case span")
+	}
+}
+
+func TestGetPaymentRecordsError(t *testing.T) {
+	exporter := tracingtest.Record(t)
+
+	uc := usecase.NewPaymentUseCase(&paymentRepository{}, nil, nil, nil)
+	if _, err := uc.GetPayment(context.Background(), "pay_missing"); err == nil {
+		t.Fatal("GetPayment succeeded, want not found")
+	}
+
+	span := tracingtest.Span(t, exporter, "PaymentUseCase.GetPayment")
+	if span.Status.Code != codes.Error || span.Status.Description != "not_found: payment not found" {
+		t.Errorf("span status = %v %q, want error %q", span.Status.Code, span.Status.Description, "not_found: payment not found")
+	}
+}
@@ -0,0 +1,180 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"GO-API/internal/domain/model"
+	"GO-API/internal/gateway"
+	"GO-API/internal/pkg/logger"
+)
+
+const (
+	DefaultReceiptProviso  = "お品代として"
+	MaxRecipientNameLength = 100
+	MaxProvisoLength       = 100
+	MaxReissueReasonLength = 200
+)
+
+type ReceiptUseCase struct {
+	repo        gateway.ReceiptRepository
+	paymentRepo gateway.PaymentRepository
+	merchants   *MerchantUseCase
+}
+
+func NewReceiptUseCase(repo gateway.ReceiptRepository, paymentRepo gateway.PaymentRepository, merchants *MerchantUseCase) *ReceiptUseCase {
+	return &ReceiptUseCase{
+		repo:        repo,
+		paymentRepo: paymentRepo,
+		merchants:   merchants,
+	}
+}
+
+type IssueReceiptInput struct {
+	PaymentID     string
+	RecipientName string
+	Proviso       string
+}
+
+// IssueReceipt issues the original receipt for a completed payment. Issuing
+// is idempotent: once a receipt exists it is returned unchanged, and the
+// recipient name and proviso of later calls are ignored.
+func (uc *ReceiptUseCase) IssueReceipt(ctx context.Context, input IssueReceiptInput) (*model.Receipt, error) {
+	logger.InfoContext(ctx, "Issuing receipt for payment", "payment_id", input.PaymentID)
+
+	payment, err := uc.findPayment(ctx, input.PaymentID)
+	if err != nil {
+		return nil, err
+	}
+
+	receipt, err := uc.repo.FindByPaymentID(ctx, merchantID(ctx), livemode(ctx), input.PaymentID)
+	if err == nil {
+		return receipt, nil
+	}
+	if !isNotFound(err) {
+		logger.ErrorContext(ctx, "Failed to find receipt", "error", err)
+		return nil, err
+	}
+
+	if input.Proviso == "" {
+		input.Proviso = DefaultReceiptProviso
+	}
+	if len(input.RecipientName) > MaxRecipientNameLength {
+		return nil, model.NewValidationError("recipient_name is too long")
+	}
+	if len(input.Proviso) > MaxProvisoLength {
+		return nil, model.NewValidationError("proviso is too long")
+	}
+
+	if payment.Status != model.PaymentStatusCompleted {
+		return nil, model.NewValidationError("receipts are only available for completed payments")
+	}
+
+	merchant, err := uc.merchants.Config(ctx, payment.MerchantID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	receiptNumber, err := uc.repo.NextReceiptNumber(ctx, now)
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to allocate receipt number", "error", err)
+		return nil, model.NewInternalError(err)
+	}
+
+	receipt = &model.Receipt{
+		ID:            uuid.New().String(),
+		MerchantID:    payment.MerchantID,
+		Livemode:      payment.Livemode,
+		ReceiptNumber: receiptNumber,
+		PaymentID:     payment.ID,
+		TransactionID: payment.TransactionID,
+		Amount:        payment.Amount,
+		Currency:      payment.Currency,
+		RecipientName: input.RecipientName,
+		Proviso:       input.Proviso,
+		Issuer:        merchant.InvoiceIssuer,
+		PaidAt:        payment.UpdatedAt,
+		IssuedAt:      now,
+	}
+
+	created, err := uc.repo.Create(ctx, receipt)
+	if err != nil {
+		logger.ErrorContext(ctx, "Database error", "error", err)
+		return nil, model.NewInternalError(err)
+	}
+	if !created {
+		// A concurrent request issued the receipt first.
+		return uc.repo.FindByPaymentID(ctx, merchantID(ctx), livemode(ctx), input.PaymentID)
+	}
+
+	logger.InfoContext(ctx, "Issued receipt for payment", "receipt_number", receipt.ReceiptNumber, "payment_id", payment.ID)
+	return receipt, nil
+}
+
+// GetReceipt returns the receipt issued for a payment without changing it.
+func (uc *ReceiptUseCase) GetReceipt(ctx context.Context, paymentID string) (*model.Receipt, error) {
+	logger.InfoContext(ctx, "Getting receipt for payment", "payment_id", paymentID)
+
+	if _, err := uc.findPayment(ctx, paymentID); err != nil {
+		return nil, err
+	}
+
+	receipt, err := uc.repo.FindByPaymentID(ctx, merchantID(ctx), livemode(ctx), paymentID)
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to find receipt", "error", err)
+		return nil, err
+	}
+	return receipt, nil
+}
+
+type ReissueReceiptInput struct {
+	PaymentID string
+	Reason    string
+}
+
+// ReissueReceipt marks the payment's receipt as reissued. Every copy rendered
+// afterwards carries the reissue mark.
+func (uc *ReceiptUseCase) ReissueReceipt(ctx context.Context, input ReissueReceiptInput) (*model.Receipt, error) {
+	logger.InfoContext(ctx, "Reissuing receipt for payment", "payment_id", input.PaymentID)
+
+	if input.Reason == "" {
+		return nil, model.NewValidationError("reason is required")
+	}
+	if len(input.Reason) > MaxReissueReasonLength {
+		return nil, model.NewValidationError("reason is too long")
+	}
+
+	receipt, err := uc.GetReceipt(ctx, input.PaymentID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	receipt.ReissuedAt = &now
+	receipt.ReissueReason = input.Reason
+
+	if err := uc.repo.Reissue(ctx, receipt); err != nil {
+		logger.ErrorContext(ctx, "Failed to reissue receipt", "error", err)
+		return nil, model.NewInternalError(err)
+	}
+
+	logger.InfoContext(ctx, "Reissued receipt", "receipt_number", receipt.ReceiptNumber, "reissue_count", receipt.ReissueCount)
+	return receipt, nil
+}
+
+// findPayment loads a payment of the merchant acting in ctx. Payments made in
+// the other mode are reported as not found.
+func (uc *ReceiptUseCase) findPayment(ctx context.Context, id string) (*model.Payment, error) {
+	payment, err := uc.paymentRepo.FindByID(ctx, merchantID(ctx), id)
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to find payment", "error", err)
+		return nil, err
+	}
+	if payment.Livemode != livemode(ctx) {
+		return nil, model.NewNotFoundError("payment not found")
+	}
+	return payment, nil
+}
@@ -0,0 +1,418 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"GO-API/internal/domain/model"
+	"GO-API/internal/domain/service"
+	"GO-API/internal/gateway"
+	"GO-API/internal/pkg/logger"
+)
+
+const (
+	MaxTrialPeriodDays   = 730
+	RenewalBatchSize     = 100
+	DefaultIntervalCount = 1
+	// RenewalClaimDuration is how long a replica holds due subscriptions it
+	// claimed before another replica may retry them.
+	RenewalClaimDuration = 10 * time.Minute
+)
+
+// DunningRetrySchedule is the delay before each retry of a failed renewal.
+// Once every retry has failed the subscription is canceled.
+var DunningRetrySchedule = []time.Duration{
+	24 * time.Hour,
+	3 * 24 * time.Hour,
+	5 * 24 * time.Hour,
+}
+
+type SubscriptionUseCase struct {
+	planRepo         gateway.PlanRepository
+	subscriptionRepo gateway.SubscriptionRepository
+	paymentUseCase   *PaymentUseCase
+	merchants        *MerchantUseCase
+}
+
+func NewSubscriptionUseCase(planRepo gateway.PlanRepository, subscriptionRepo gateway.SubscriptionRepository, paymentUseCase *PaymentUseCase, merchants *MerchantUseCase) *SubscriptionUseCase {
+	return &SubscriptionUseCase{
+		planRepo:         planRepo,
+		subscriptionRepo: subscriptionRepo,
+		paymentUseCase:   paymentUseCase,
+		merchants:        merchants,
+	}
+}
+
+type CreatePlanInput struct {
+	Name            string
+	Amount          int64
+	Currency        string
+	Interval        string
+	IntervalCount   int
+	TrialPeriodDays int
+}
+
+func (uc *SubscriptionUseCase) CreatePlan(ctx context.Context, input CreatePlanInput) (*model.Plan, error) {
+	logger.InfoContext(ctx, "Creating plan", "name", input.Name, "amount", input.Amount, "currency", input.Currency, "interval", input.Interval)
+
+	if input.IntervalCount == 0 {
+		input.IntervalCount = DefaultIntervalCount
+	}
+
+	merchant, err := uc.merchants.Config(ctx, merchantID(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateCreatePlanInput(input, merchant); err != nil {
+		logger.ErrorContext(ctx, "Plan validation failed", "error", err)
+		return nil, err
+	}
+
+	now := time.Now()
+	plan := &model.Plan{
+		ID:              uuid.New().String(),
+		MerchantID:      merchant.ID,
+		Livemode:        livemode(ctx),
+		Name:            input.Name,
+		Amount:          input.Amount,
+		Currency:        input.Currency,
+		Interval:        model.PlanInterval(input.Interval),
+		IntervalCount:   input.IntervalCount,
+		TrialPeriodDays: input.TrialPeriodDays,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+
+	if err := uc.planRepo.Create(ctx, plan); err != nil {
+		logger.ErrorContext(ctx, "Database error", "error", err)
+		return nil, model.NewInternalError(err)
+	}
+
+	logger.InfoContext(ctx, "Successfully created plan", "plan_id", plan.ID)
+	return plan, nil
+}
+
+func validateCreatePlanInput(input CreatePlanInput, merchant *model.Merchant) error {
+	if input.Name == "" {
+		return model.NewValidationError("name is required")
+	}
+	if input.Amount < merchant.MinAmount || input.Amount > merchant.MaxAmount {
+		return model.NewValidationError("amount is out of range")
+	}
+	if !merchant.AllowsCurrency(input.Currency) {
+		return model.NewValidationError("unsupported currency")
+	}
+	if !service.ValidPlanInterval(model.PlanInterval(input.Interval)) {
+		return model.NewValidationError("unsupported interval")
+	}
+	if input.IntervalCount < 1 {
+		return model.NewValidationError("interval_count must be positive")
+	}
+	if input.TrialPeriodDays < 0 || input.TrialPeriodDays > MaxTrialPeriodDays {
+		return model.NewValidationError("trial_period_days is out of range")
+	}
+	return nil
+}
+
+func (uc *SubscriptionUseCase) GetPlan(ctx context.Context, id string) (*model.Plan, error) {
+	logger.InfoContext(ctx, "Getting plan", "plan_id", id)
+
+	plan, err := uc.planRepo.FindByID(ctx, merchantID(ctx), livemode(ctx), id)
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to find plan", "error", err)
+		return nil, err
+	}
+	return plan, nil
+}
+
+func (uc *SubscriptionUseCase) ListPlans(ctx context.Context, limit, offset int) ([]*model.Plan, error) {
+	logger.InfoContext(ctx, "Listing plans", "limit", limit, "offset", offset)
+
+	if limit <= 0 {
+		limit = 10
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	plans, err := uc.planRepo.List(ctx, merchantID(ctx), livemode(ctx), limit, offset)
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to list plans", "error", err)
+		return nil, err
+	}
+	return plans, nil
+}
+
+type CreateSubscriptionInput struct {
+	CustomerID    string
+	PlanID        string
+	PaymentMethod string
+	// TrialPeriodDays overrides the plan's trial length when non-nil.
+	TrialPeriodDays *int
+	// BillingAnchor fixes the date renewals are aligned to. Defaults to the
+	// end of the trial, or to the creation time when there is no trial.
+	BillingAnchor *time.Time
+}
+
+func (uc *SubscriptionUseCase) CreateSubscription(ctx context.Context, input CreateSubscriptionInput) (*model.Subscription, error) {
+	logger.InfoContext(ctx, "Creating subscription", "customer_id", input.CustomerID, "plan_id", input.PlanID)
+
+	if input.CustomerID == "" {
+		return nil, model.NewValidationError("customer_id is required")
+	}
+	merchant, err := uc.merchants.Config(ctx, merchantID(ctx))
+	if err != nil {
+		return nil, err
+	}
+	if err := validatePaymentMethod(input.PaymentMethod, merchant); err != nil {
+		return nil, err
+	}
+
+	plan, err := uc.planRepo.FindByID(ctx, merchant.ID, livemode(ctx), input.PlanID)
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to find plan", "error", err)
+		return nil, err
+	}
+
+	trialDays := plan.TrialPeriodDays
+	if input.TrialPeriodDays != nil {
+		trialDays = *input.TrialPeriodDays
+	}
+	if trialDays < 0 || trialDays > MaxTrialPeriodDays {
+		return nil, model.NewValidationError("trial_period_days is out of range")
+	}
+
+	now := time.Now()
+	subscription := &model.Subscription{
+		ID:                 uuid.New().String(),
+		MerchantID:         merchant.ID,
+		Livemode:           livemode(ctx),
+		CustomerID:         input.CustomerID,
+		PlanID:             plan.ID,
+		PaymentMethod:      input.PaymentMethod,
+		Status:             model.SubscriptionStatusActive,
+		CurrentPeriodStart: now,
+		CreatedAt:          now,
+		UpdatedAt:          now,
+	}
+
+	periodStart := now
+	if trialDays > 0 {
+		trialEnd := now.AddDate(0, 0, trialDays)
+		subscription.TrialEnd = &trialEnd
+		periodStart = trialEnd
+	}
+
+	subscription.BillingAnchor = periodStart
+	if input.BillingAnchor != nil {
+		if input.BillingAnchor.Before(now) {
+			return nil, model.NewValidationError("billing_anchor must not be in the past")
+		}
+		subscription.BillingAnchor = *input.BillingAnchor
+	}
+
+	if subscription.TrialEnd != nil {
+		subscription.CurrentPeriodEnd = *subscription.TrialEnd
+	} else {
+		subscription.CurrentPeriodEnd = service.NextBillingDate(subscription.BillingAnchor, now, plan.Interval, plan.IntervalCount)
+		subscription.Status = model.SubscriptionStatusIncomplete
+	}
+
+	// The subscription is stored before it is charged so that a payment is
+	// never taken for a subscription that does not exist.
+	if err := uc.subscriptionRepo.Create(ctx, subscription); err != nil {
+		logger.ErrorContext(ctx, "Database error", "error", err)
+		return nil, model.NewInternalError(err)
+	}
+
+	if subscription.Status == model.SubscriptionStatusIncomplete {
+		payment, err := uc.charge(ctx, subscription, plan, subscription.CurrentPeriodStart)
+		if err != nil {
+			logger.ErrorContext(ctx, "Initial subscription payment failed", "error", err)
+			cancelSubscription(subscription, time.Now())
+			if err := uc.subscriptionRepo.Update(ctx, subscription); err != nil {
+				logger.ErrorContext(ctx, "Failed to cancel incomplete subscription", "error", err)
+			}
+			return nil, err
+		}
+
+		subscription.LastPaymentID = payment.ID
+		subscription.Status = model.SubscriptionStatusActive
+		if err := uc.subscriptionRepo.Update(ctx, subscription); err != nil {
+			logger.ErrorContext(ctx, "Failed to activate subscription", "error", err)
+			return nil, model.NewInternalError(err)
+		}
+	}
+
+	logger.InfoContext(ctx, "Successfully created subscription", "subscription_id", subscription.ID)
+	return subscription, nil
+}
+
+func (uc *SubscriptionUseCase) GetSubscription(ctx context.Context, id string) (*model.Subscription, error) {
+	logger.InfoContext(ctx, "Getting subscription", "subscription_id", id)
+
+	subscription, err := uc.findSubscription(ctx, id)
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to find subscription", "error", err)
+		return nil, err
+	}
+	return subscription, nil
+}
+
+func (uc *SubscriptionUseCase) ListSubscriptions(ctx context.Context, limit, offset int) ([]*model.Subscription, error) {
+	logger.InfoContext(ctx, "Listing subscriptions", "limit", limit, "offset", offset)
+
+	if limit <= 0 {
+		limit = 10
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	subscriptions, err := uc.subscriptionRepo.List(ctx, merchantID(ctx), livemode(ctx), limit, offset)
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to list subscriptions", "error", err)
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+func (uc *SubscriptionUseCase) CancelSubscription(ctx context.Context, id string, atPeriodEnd bool) (*model.Subscription, error) {
+	logger.InfoContext(ctx, "Canceling subscription", "subscription_id", id, "at_period_end", atPeriodEnd)
+
+	subscription, err := uc.findSubscription(ctx, id)
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to find subscription", "error", err)
+		return nil, err
+	}
+
+	if subscription.Status == model.SubscriptionStatusCanceled {
+		return nil, model.NewValidationError("subscription is already canceled")
+	}
+
+	if atPeriodEnd {
+		subscription.CancelAtPeriodEnd = true
+	} else {
+		cancelSubscription(subscription, time.Now())
+	}
+
+	if err := uc.subscriptionRepo.Update(ctx, subscription); err != nil {
+		logger.ErrorContext(ctx, "Failed to update subscription", "error", err)
+		return nil, err
+	}
+
+	logger.InfoContext(ctx, "Successfully canceled subscription", "subscription_id", subscription.ID)
+	return subscription, nil
+}
+
+// findSubscription loads a subscription of the merchant acting in ctx.
+// Subscriptions created in the other mode are reported as not found.
+func (uc *SubscriptionUseCase) findSubscription(ctx context.Context, id string) (*model.Subscription, error) {
+	subscription, err := uc.subscriptionRepo.FindByID(ctx, merchantID(ctx), id)
+	if err != nil {
+		return nil, err
+	}
+	if subscription.Livemode != livemode(ctx) {
+		return nil, model.NewNotFoundError("subscription not found")
+	}
+	return subscription, nil
+}
+
+// RenewDueSubscriptions bills every subscription whose period has ended or
+// whose dunning retry is due. It is invoked periodically by the scheduler on
+// every replica; each due subscription is claimed by only one of them.
+func (uc *SubscriptionUseCase) RenewDueSubscriptions(ctx context.Context) error {
+	now := time.Now()
+
+	subscriptions, err := uc.subscriptionRepo.ClaimDue(ctx, now, now.Add(RenewalClaimDuration), RenewalBatchSize)
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to claim due subscriptions", "error", err)
+		return err
+	}
+
+	if len(subscriptions) > 0 {
+		logger.InfoContext(ctx, "Renewing due subscriptions", "count", len(subscriptions))
+	}
+
+	for _, subscription := range subscriptions {
+		if err := uc.renew(withScope(ctx, subscription.MerchantID, subscription.Livemode), subscription, now); err != nil {
+			logger.ErrorContext(ctx, "Failed to renew subscription", "subscription_id", subscription.ID, "error", err)
+		}
+	}
+	return nil
+}
+
+func (uc *SubscriptionUseCase) renew(ctx context.Context, subscription *model.Subscription, now time.Time) error {
+	if subscription.CancelAtPeriodEnd && subscription.Status == model.SubscriptionStatusActive {
+		logger.InfoContext(ctx, "Subscription reached period end with cancel_at_period_end", "subscription_id", subscription.ID)
+		cancelSubscription(subscription, now)
+		return uc.subscriptionRepo.Update(ctx, subscription)
+	}
+
+	plan, err := uc.planRepo.FindByID(ctx, subscription.MerchantID, subscription.Livemode, subscription.PlanID)
+	if err != nil {
+		return err
+	}
+
+	payment, err := uc.charge(ctx, subscription, plan, subscription.CurrentPeriodEnd)
+	if err != nil {
+		logger.ErrorContext(ctx, "Renewal payment failed for subscription", "subscription_id", subscription.ID, "error", err)
+		scheduleRetry(ctx, subscription, now)
+		return uc.subscriptionRepo.Update(ctx, subscription)
+	}
+
+	subscription.LastPaymentID = payment.ID
+	subscription.Status = model.SubscriptionStatusActive
+	subscription.RetryCount = 0
+	subscription.NextRetryAt = nil
+	subscription.CurrentPeriodStart = subscription.CurrentPeriodEnd
+	subscription.CurrentPeriodEnd = service.NextBillingDate(subscription.BillingAnchor, subscription.CurrentPeriodEnd, plan.Interval, plan.IntervalCount)
+
+	logger.InfoContext(ctx, "Renewed subscription", "subscription_id", subscription.ID, "current_period_end", subscription.CurrentPeriodEnd)
+	return uc.subscriptionRepo.Update(ctx, subscription)
+}
+
+// charge bills the period starting at periodStart. The payment is keyed by the
+// period and the dunning attempt, so charging again after a failure to
+// persist the outcome returns the earlier payment instead of billing twice.
+func (uc *SubscriptionUseCase) charge(ctx context.Context, subscription *model.Subscription, plan *model.Plan, periodStart time.Time) (*model.Payment, error) {
+	payment, err := uc.paymentUseCase.CreatePayment(ctx, CreatePaymentInput{
+		Amount:         plan.Amount,
+		Currency:       plan.Currency,
+		Description:    fmt.Sprintf("Subscription %s (%s)", plan.Name, subscription.ID),
+		CustomerID:     subscription.CustomerID,
+		PaymentMethod:  subscription.PaymentMethod,
+		SubscriptionID: subscription.ID,
+		IdempotencyKey: fmt.Sprintf("subscription:%s:%d:%d", subscription.ID, periodStart.Unix(), subscription.RetryCount),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if payment.Status == model.PaymentStatusFailed {
+		return nil, model.NewInternalError(fmt.Errorf("payment %s failed", payment.ID))
+	}
+	return payment, nil
+}
+
+func scheduleRetry(ctx context.Context, subscription *model.Subscription, now time.Time) {
+	if subscription.RetryCount >= len(DunningRetrySchedule) {
+		logger.InfoContext(ctx, "Dunning retries exhausted, canceling subscription", "subscription_id", subscription.ID)
+		cancelSubscription(subscription, now)
+		return
+	}
+
+	nextRetry := now.Add(DunningRetrySchedule[subscription.RetryCount])
+	subscription.RetryCount++
+	subscription.NextRetryAt = &nextRetry
+	subscription.Status = model.SubscriptionStatusPastDue
+}
+
+func cancelSubscription(subscription *model.Subscription, now time.Time) {
+	subscription.Status = model.SubscriptionStatusCanceled
+	subscription.CanceledAt = &now
+	subscription.NextRetryAt = nil
+}
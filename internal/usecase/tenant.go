@@ -0,0 +1,48 @@
+package usecase
+
+import (
+	"context"
+
+	"GO-API/internal/pkg/auth"
+	"GO-API/internal/pkg/logger"
+)
+
+type scopeContextKey struct{}
+
+type scope struct {
+	merchantID string
+	livemode   bool
+}
+
+// withScope scopes ctx to a merchant and mode for work that runs without an
+// authenticated principal, such as scheduler jobs acting on a merchant's
+// records.
+func withScope(ctx context.Context, merchantID string, livemode bool) context.Context {
+	ctx = logger.WithMerchantID(ctx, merchantID)
+	return context.WithValue(ctx, scopeContextKey{}, scope{merchantID: merchantID, livemode: livemode})
+}
+
+// merchantID is the merchant every repository call made on behalf of ctx is
+// restricted to. It is empty, and therefore matches nothing, when ctx carries
+// neither a principal nor an explicit scope.
+func merchantID(ctx context.Context) string {
+	if s, ok := ctx.Value(scopeContextKey{}).(scope); ok {
+		return s.merchantID
+	}
+	if principal, ok := auth.PrincipalFromContext(ctx); ok {
+		return principal.MerchantID
+	}
+	return ""
+}
+
+// livemode reports whether ctx acts on live data. Only test API keys, test
+// tokens and work scoped to test records run in test mode.
+func livemode(ctx context.Context) bool {
+	if s, ok := ctx.Value(scopeContextKey{}).(scope); ok {
+		return s.livemode
+	}
+	if principal, ok := auth.PrincipalFromContext(ctx); ok {
+		return principal.Livemode
+	}
+	return true
+}
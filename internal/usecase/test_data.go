@@ -0,0 +1,42 @@
+package usecase
+
+import (
+	"context"
+
+	"GO-API/internal/domain/model"
+	"GO-API/internal/gateway"
+	"GO-API/internal/pkg/logger"
+)
+
+const AuditActionTestDataDelete = "test_data.delete"
+
+type TestDataUseCase struct {
+	repo  gateway.TestDataRepository
+	audit *AuditUseCase
+}
+
+func NewTestDataUseCase(repo gateway.TestDataRepository, audit *AuditUseCase) *TestDataUseCase {
+	return &TestDataUseCase{
+		repo:  repo,
+		audit: audit,
+	}
+}
+
+// DeleteTestData removes all test mode records of the merchant acting in ctx.
+// Live data is never touched. Test mode API keys and API clients are kept:
+// they are credentials rather than data, and the caller may be using one.
+func (uc *TestDataUseCase) DeleteTestData(ctx context.Context) (*model.TestDataDeletion, error) {
+	id := merchantID(ctx)
+	if id == "" {
+		return nil, model.NewUnauthorizedError("no merchant associated with the credentials")
+	}
+
+	result, err := uc.repo.DeleteTestData(ctx, id)
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to delete test data", "error", err)
+		return nil, model.NewInternalError(err)
+	}
+
+	uc.audit.Record(ctx, AuditActionTestDataDelete, AuditResourceMerchant, id)
+	return result, nil
+}